@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/komem3/goalarm/internal/config"
+)
+
+// loadConfig sets flags not given by args from environment variables and config file.
+// Precedence is flags > environment variables(GOALARM_*) > config file.
+func (e *flagPaser) loadConfig() error {
+	given := make(map[string]bool)
+	e.fset.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	var err error
+	e.fset.VisitAll(func(f *flag.Flag) {
+		name := config.EnvName(f.Name)
+		v, ok := e.lookupEnv(name)
+		if err != nil || given[f.Name] || !ok {
+			return
+		}
+		if serr := e.fset.Set(f.Name, v); serr != nil {
+			err = fmt.Errorf("%s: %w", name, serr)
+			return
+		}
+		given[f.Name] = true
+	})
+	if err != nil {
+		return err
+	}
+
+	optional := false
+	if e.cfgFile == "" {
+		if e.cfgFile, err = config.DefaultPath(); err != nil {
+			return nil
+		}
+		optional = true
+	}
+	if e.cfg, err = config.Load(e.cfgFile, optional); err != nil {
+		return err
+	}
+	flags := e.cfg.Flags()
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if given[name] {
+			continue
+		}
+		if err := e.fset.Set(name, flags[name]); err != nil {
+			return fmt.Errorf("config: %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// effectiveConfig returns config of current flags.
+func (e *flagPaser) effectiveConfig() config.Config {
+	return config.Config{
+		Sound: config.Sound{
+			File:        e.file,
+			Order:       e.order,
+			Tone:        e.tone,
+			Silent:      e.silent,
+			Announce:    e.announce,
+			Volume:      e.volume,
+			FadeIn:      durationString(e.fadeIn),
+			Repeat:      e.repeat,
+			Interval:    durationString(e.interval),
+			MaxDuration: durationString(e.maxDur),
+		},
+		Output: config.Output{
+			Format:   e.format,
+			Template: e.template,
+			Tick:     durationString(e.tick),
+		},
+		Command: config.Command{
+			Strict: e.strict,
+			OnEOF:  e.onEOF,
+		},
+		Log: config.Log{
+			Level:   e.logLevel,
+			Format:  e.logFmt,
+			File:    e.logFile,
+			MaxSize: e.logSize,
+			Backups: e.logKeep,
+		},
+		Metrics: config.Metrics{
+			Addr: e.metrics,
+		},
+		Routines: e.cfg.Routines,
+		Hooks:    e.cfg.Hooks,
+	}
+}
+
+// showConfig writes the effective config as toml.
+func (e *flagPaser) showConfig(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "# %s\n", e.cfgFile); err != nil {
+		return err
+	}
+	return e.effectiveConfig().Write(w)
+}
+
+func durationString(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	err := ioutil.WriteFile(path, []byte(`
+[sound]
+file = "./bell.mp3"
+volume = "50%"
+fade_in = "10s"
+
+[output]
+format = "text"
+tick = "1s"
+
+[[routines.pomodoro]]
+range = 25
+name = "working"
+
+[hooks]
+finish = "echo finish"
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{
+		"GOALARM_CONFIG": path,
+		"GOALARM_VOLUME": "30%",
+		"GOALARM_TICK":   "5s",
+		"GOALARM_STRICT": "true",
+	}
+
+	parser := newParser()
+	parser.lookupEnv = func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+	if err := parser.parse([]string{"-tick", "2s", "-routine", "pomodoro"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.loadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := parser.showConfig(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# " + path + `
+[sound]
+  file = "./bell.mp3"
+  order = "sequential"
+  volume = "30%"
+  fade_in = "10s"
+
+[output]
+  format = "text"
+  tick = "2s"
+
+[command]
+  strict = true
+  on_eof = "stop"
+
+[log]
+  level = "error"
+  format = "text"
+  max_size = 10
+  backups = 3
+
+[metrics]
+
+[routines]
+
+  [[routines.pomodoro]]
+    name = "working"
+    range = 25
+
+[hooks]
+  finish = "echo finish"
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+
+	routine, err := parser.loadRoutine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(routine[0].Task.Name, "working"); diff != "" {
+		t.Errorf("routine: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(len(parser.config().Subscribers), 1); diff != "" {
+		t.Errorf("hooks: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestLoadConfig_Template(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	err := ioutil.WriteFile(path, []byte(`
+[routines.pomodoro]
+params = { work = "25m", break = "5m" }
+
+[[routines.pomodoro.routine]]
+range = "{{work}}"
+name = "working"
+
+[[routines.pomodoro.routine]]
+range = "{{break}}"
+name = "break"
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser := newParser()
+	parser.lookupEnv = func(string) (string, bool) { return "", false }
+	if err := parser.parse([]string{"-config", path, "-routine", "pomodoro", "-set", "work=50m"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.loadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	routine, err := parser.loadRoutine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var given []time.Duration
+	for _, step := range routine {
+		given = append(given, step.Range)
+	}
+	if diff := cmp.Diff(given, []time.Duration{time.Minute * 50, time.Minute * 5}); diff != "" {
+		t.Errorf("ranges: given(-), want(+)\n%s\n", diff)
+	}
+}
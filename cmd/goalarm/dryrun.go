@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	rtn "github.com/komem3/goalarm/internal/routine"
+)
+
+const timelineLayout = "2006-01-02 15:04:05"
+
+// printTimeline validates sounds of routine, and writes the timeline started at start.
+// routine must be validated by Routine.Validate.
+// Timers and speaker are not used. Looping routine is expanded -dry-run-loops times.
+func (e *flagPaser) printTimeline(w io.Writer, routine rtn.Routine, start time.Time) error {
+	if err := routine.ValidateSound(e.sound()); err != nil {
+		return err
+	}
+	loops := 1
+	if e.loop {
+		loops = e.dryLoops
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "LOOP\tSTEP\tNAME\tSTART\tEND\tRANGE")
+	for _, s := range rtn.Timeline(routine, start, loops) {
+		// times are rounded to seconds, which the layout shows.
+		start, end := s.Start.Round(time.Second), s.End.Round(time.Second)
+		fmt.Fprintf(tw, "%d\t%d/%d\t%s\t%s\t%s\t%s",
+			s.Loop, s.Index, s.Total, s.Name,
+			start.Format(timelineLayout), end.Format(timelineLayout), end.Sub(start))
+		if s.NextDay {
+			fmt.Fprint(tw, "\tuntil has passed, ends next day")
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
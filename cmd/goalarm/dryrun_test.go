@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	rtn "github.com/komem3/goalarm/internal/routine"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+func TestPrintTimeline(t *testing.T) {
+	parser := newParser()
+	err := parser.parse([]string{"-silent", "-loop", "-dry-run-loops", "2", "-routine",
+		`[{"index":2,"range":5,"name":"break"},{"index":1,"range":25,"name":"working"}]`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	routine, err := parser.loadRoutine()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	start := time.Date(2021, 1, 1, 23, 30, 0, 0, time.UTC)
+	if err := parser.printTimeline(&buf, routine, start); err != nil {
+		t.Fatal(err)
+	}
+	want := `LOOP  STEP  NAME     START                END                  RANGE
+0     1/2   working  2021-01-01 23:30:00  2021-01-01 23:55:00  25m0s
+0     2/2   break    2021-01-01 23:55:00  2021-01-02 00:00:00  5m0s
+1     1/2   working  2021-01-02 00:00:00  2021-01-02 00:25:00  25m0s
+1     2/2   break    2021-01-02 00:25:00  2021-01-02 00:30:00  5m0s
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestPrintTimeline_Until(t *testing.T) {
+	parser := newParser()
+	err := parser.parse([]string{"-silent", "-routine",
+		`[{"range":30,"name":"welcome"},{"until":"10:30","name":"keynote"},{"range":"15m","name":"break"}]`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	routine, err := parser.loadRoutine()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	start := time.Date(2021, 1, 1, 9, 45, 0, 0, time.UTC)
+	if err := parser.printTimeline(&buf, routine, start); err != nil {
+		t.Fatal(err)
+	}
+	want := `LOOP  STEP  NAME     START                END                  RANGE
+0     1/3   welcome  2021-01-01 09:45:00  2021-01-01 10:15:00  30m0s
+0     2/3   keynote  2021-01-01 10:15:00  2021-01-01 10:30:00  15m0s
+0     3/3   break    2021-01-01 10:30:00  2021-01-01 10:45:00  15m0s
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestPrintTimeline_UntilPassed(t *testing.T) {
+	parser := newParser()
+	routine := rtn.Routine{
+		{Task: timeserver.Task{Range: time.Minute * 30, Name: "welcome"}},
+		{Task: timeserver.Task{Name: "keynote"}, Until: clock(t, "10:00")},
+	}
+
+	var buf bytes.Buffer
+	start := time.Date(2021, 1, 1, 9, 45, 0, 0, time.UTC)
+	if err := parser.printTimeline(&buf, routine, start); err != nil {
+		t.Fatal(err)
+	}
+	want := `LOOP  STEP  NAME     START                END                  RANGE
+0     1/2   welcome  2021-01-01 09:45:00  2021-01-01 10:15:00  30m0s
+0     2/2   keynote  2021-01-01 10:15:00  2021-01-02 10:00:00  23h45m0s  until has passed, ends next day
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func clock(t *testing.T, s string) *rtn.Clock {
+	t.Helper()
+	c, err := rtn.ParseClock(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &c
+}
+
+func TestPrintTimeline_Round(t *testing.T) {
+	parser := newParser()
+	start := time.Date(2021, 1, 1, 9, 45, 0, int(time.Millisecond*600), time.UTC)
+	routine := rtn.Routine{{Task: timeserver.Task{Range: time.Hour - time.Millisecond*935, Name: "alarm"}}}
+
+	var buf bytes.Buffer
+	if err := parser.printTimeline(&buf, routine, start); err != nil {
+		t.Fatal(err)
+	}
+	want := `LOOP  STEP  NAME   START                END                  RANGE
+0     1/1   alarm  2021-01-01 09:45:01  2021-01-01 10:45:00  59m59s
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+}
@@ -1,41 +1,122 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/komem3/goalarm/internal/config"
+	"github.com/komem3/goalarm/internal/hook"
 	"github.com/komem3/goalarm/internal/log"
+	"github.com/komem3/goalarm/internal/metrics"
+	"github.com/komem3/goalarm/internal/output"
 	rtn "github.com/komem3/goalarm/internal/routine"
+	"github.com/komem3/goalarm/internal/sound"
 	"github.com/komem3/goalarm/internal/timeserver"
+	"github.com/komem3/goalarm/internal/tui"
 )
 
 type flagPaser struct {
 	fset     *flag.FlagSet
 	file     string
+	order    string
+	tone     string
+	silent   bool
+	wavOut   string
+	announce string
+	volume   string
+	fadeIn   time.Duration
+	repeat   int
+	interval time.Duration
+	maxDur   time.Duration
+	warnings durationsFlag
+	warnFile string
+	warnTone string
 	sec      int64
 	min      int64
 	hour     int64
 	time     string
+	tomorrow bool
 	routine  string
+	rtnFile  string
+	vars     setFlag
 	loop     bool
 	describe string
+	tui      bool
+	format   string
+	template string
+	tick     time.Duration
+	strict   bool
+	onEOF    string
+	metrics  string
+	logLevel string
+	logFmt   string
+	logFile  string
+	logSize  int64
+	logKeep  int
 	verbose  bool
+	cfgFile  string
+	dryRun   bool
+	dryLoops int
+
+	cfg       config.Config
+	lookupEnv func(key string) (string, bool)
 }
 
 func newParser() *flagPaser {
-	e := &flagPaser{fset: flag.NewFlagSet("goalarm", flag.ExitOnError)}
-	e.fset.StringVar(&e.file, "file", "", "Path of sound file.")
+	e := &flagPaser{
+		fset:      flag.NewFlagSet("goalarm", flag.ExitOnError),
+		lookupEnv: os.LookupEnv,
+	}
+	e.fset.StringVar(&e.file, "file", "", "Path of sound file. Support mp3, wav, flac and ogg. Directory or glob pattern plays one of the files.")
+	e.fset.StringVar(&e.order, "order", string(sound.SequentialOrder), "Order of sound files. sequential or random.")
+	e.fset.StringVar(&e.tone, "tone", "", fmt.Sprintf("Generated tone used when file is empty.(%s)", sound.DefaultTone))
+	e.fset.BoolVar(&e.silent, "silent", false, "Play no sound. Audio device is not required.")
+	e.fset.StringVar(&e.wavOut, "wav-out", "", "Write sound to wav file instead of speaker.")
+	e.fset.StringVar(&e.announce, "announce", "", "Command speaking next task of routine after sound. It reads text from stdin and writes wav to stdout.(espeak --stdout)")
+	e.fset.StringVar(&e.volume, "volume", "", "Volume of sound. Percent(50%) or dB(-6db).")
+	e.fset.DurationVar(&e.fadeIn, "fade-in", 0, "Fade in duration of sound.(10s)")
+	e.fset.IntVar(&e.repeat, "repeat", 0, "Play count of sound.")
+	e.fset.DurationVar(&e.interval, "interval", 0, "Interval between repeated sound.(2s)")
+	e.fset.DurationVar(&e.maxDur, "max-duration", 0, "Max play duration of sound.(30s)")
+	e.fset.Var(&e.warnings, "warnings", "Left times to notify warning.(1m,10s)")
+	e.fset.StringVar(&e.warnFile, "warning-file", "", "Path of sound file played at warning.")
+	e.fset.StringVar(&e.warnTone, "warning-tone", "", "Generated tone played at warning.(440hz:100ms x2)")
 	e.fset.Int64Var(&e.sec, "sec", 0, "Wait second.")
 	e.fset.Int64Var(&e.min, "min", 0, "Wait minute.")
 	e.fset.Int64Var(&e.hour, "hour", 0, "Wait hour.")
-	e.fset.StringVar(&e.time, "time", "", "Call time.(15:00:01)")
-	e.fset.StringVar(&e.routine, "routine", "", `Alarm routine. Format is json array. [{"range":20,"name":"working"},{"range":5,"name":"break"}]`)
+	e.fset.StringVar(&e.time, "time", "", "Call time. Date is optional.(15:00:01, 2006-01-02 15:00)")
+	e.fset.BoolVar(&e.tomorrow, "tomorrow", false, "Call time of -time is tomorrow.")
+	e.fset.StringVar(&e.routine, "routine", "", `Alarm routine or name of routine in config. Format is json array. [{"range":20,"name":"working","warnings":["1m"]},{"range":5,"name":"break","sound":{"volume":"50%"}}]`)
+	e.fset.StringVar(&e.rtnFile, "routine-file", "", "Path of routine json file. SIGHUP reloads it from the next step.")
+	e.fset.Var(&e.vars, "set", "Variable of routine template. It can be repeated.(work=50m)")
 	e.fset.BoolVar(&e.loop, "loop", false, "Loop Alarm.")
 	e.fset.StringVar(&e.describe, "describe", "", "Describe command or status.")
-	e.fset.BoolVar(&e.verbose, "v", false, "Ouput verbose.")
+	e.fset.BoolVar(&e.tui, "tui", false, "Show live countdown on terminal. Keys are space(pause/start), r(restart), n(next) and q(stop).")
+	e.fset.StringVar(&e.format, "output-format", output.JSONFormat, "Output format. json, text, i3bar, waybar or tmux. Use -format for a custom line.")
+	e.fset.StringVar(&e.template, "format", "", "Go template of output line. It overrides -output-format. Fields are Status, Left, Elapsed, Progress, Deadline, Step, Total, Name, Range and Error.({{.Name}} {{.Left}} {{.Progress}}%)")
+	e.fset.DurationVar(&e.tick, "tick", 0, "Interval of writing current status.(1s)")
+	e.fset.BoolVar(&e.strict, "strict", false, "Stop alarm by command error like unknown command.")
+	e.fset.StringVar(&e.onEOF, "on-eof", string(timeserver.StopOnEOF), "Behavior when stdin is closed. stop or continue(keep running until the alarm).")
+	e.fset.StringVar(&e.metrics, "metrics-addr", "", "Address serving OpenMetrics at /metrics.(localhost:9090)")
+	e.fset.StringVar(&e.logLevel, "log-level", log.ErrorLevel.String(), "Level of log. debug, info, warn or error.")
+	e.fset.StringVar(&e.logFmt, "log-format", string(log.TextFormat), "Format of log. text or json.")
+	e.fset.StringVar(&e.logFile, "log-file", "", "Path of log file instead of stderr.")
+	e.fset.Int64Var(&e.logSize, "log-max-size", 10, "Max megabytes of log file before rotation. 0 disables rotation.")
+	e.fset.IntVar(&e.logKeep, "log-backups", 3, "Number of rotated log files to keep.")
+	e.fset.BoolVar(&e.dryRun, "dry-run", false, "Validate routine and sounds, and print the timeline without timer and sound.")
+	e.fset.IntVar(&e.dryLoops, "dry-run-loops", 3, "Number of loops printed by -dry-run with -loop.")
+	e.fset.StringVar(&e.cfgFile, "config", "", "Path of config file.($XDG_CONFIG_HOME/goalarm/config.toml)")
+	e.fset.BoolVar(&e.verbose, "v", false, "Ouput verbose. It is same as -log-level debug.")
 	return e
 }
 
@@ -43,6 +124,213 @@ func (e *flagPaser) parse(args []string) error {
 	return e.fset.Parse(args)
 }
 
+func (e *flagPaser) sound() sound.Config {
+	var files []string
+	if e.file != "" {
+		files = []string{e.file}
+	}
+	return sound.Config{
+		Files:    files,
+		Order:    sound.Order(e.order),
+		Tone:     e.tone,
+		Silent:   e.silent,
+		WavOut:   e.wavOut,
+		Announce: e.announce,
+		Effect: sound.Effect{
+			Volume:      e.volume,
+			FadeIn:      e.fadeIn,
+			Repeat:      e.repeat,
+			Interval:    e.interval,
+			MaxDuration: e.maxDur,
+		},
+	}
+}
+
+// step returns default step of alarm and routine.
+func (e *flagPaser) step() rtn.Step {
+	var files []string
+	if e.warnFile != "" {
+		files = []string{e.warnFile}
+	}
+	return rtn.Step{
+		Task: timeserver.Task{
+			Warnings: e.warnings,
+		},
+		Warning: sound.Config{
+			Files: files,
+			Tone:  e.warnTone,
+		},
+	}
+}
+
+// config returns setting of routine and alarm.
+// Output format is always json in tui mode.
+func (e *flagPaser) config() rtn.Config {
+	c := rtn.Config{
+		Sound:    e.sound(),
+		Loop:     e.loop,
+		Format:   e.format,
+		Template: e.template,
+		Tick:     e.tick,
+		Strict:   e.strict,
+		OnEOF:    timeserver.EOFPolicy(e.onEOF),
+	}
+	if statuses := e.cfg.HookStatuses(); len(statuses) > 0 {
+		handlers := make(map[timeserver.Status]timeserver.Handler, len(statuses))
+		for _, status := range statuses {
+			handlers[status] = hook.New(e.cfg.Hooks[string(status)])
+		}
+		hooks := hook.NewHooks(handlers)
+		c.OnTask = hooks.Start
+		c.Subscribers = append(c.Subscribers, rtn.Subscriber{Handler: hooks})
+	}
+	if e.tui {
+		c.Format = output.JSONFormat
+		c.Template = ""
+	}
+	return c
+}
+
+// isJSON reports whether s is json array or object rather than name of routine.
+func isJSON(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, "[") || strings.HasPrefix(s, "{")
+}
+
+// loadRoutine parses routine of -routine, -routine-file or named routine of config.
+func (e *flagPaser) loadRoutine() (rtn.Routine, error) {
+	b := []byte(e.routine)
+	switch {
+	case e.rtnFile != "":
+		var err error
+		if b, err = ioutil.ReadFile(e.rtnFile); err != nil {
+			return nil, fmt.Errorf("read routine: %w", err)
+		}
+	case !isJSON(e.routine):
+		r, err := e.cfg.Routine(e.routine)
+		if err != nil {
+			return nil, fmt.Errorf("read routine: %w", err)
+		}
+		if b, err = json.Marshal(r); err != nil {
+			return nil, fmt.Errorf("read routine: %w", err)
+		}
+	}
+	log.Debugf("input routine: %s", b)
+	b, err := expandRoutine(b, e.vars)
+	if err != nil {
+		return nil, fmt.Errorf("parse routine: %w", err)
+	}
+	var rj []taskJson
+	if err := json.Unmarshal(b, &rj); err != nil {
+		return nil, fmt.Errorf("parse routine: %w", err)
+	}
+	return convertTask(rj, e.step()), nil
+}
+
+// serveMetrics serves metrics of tasks run by c when -metrics-addr is set.
+func (e *flagPaser) serveMetrics(c *rtn.Config) (close func(), err error) {
+	if e.metrics == "" {
+		return func() {}, nil
+	}
+	l, err := net.Listen("tcp", e.metrics)
+	if err != nil {
+		return nil, fmt.Errorf("metrics: %w", err)
+	}
+	log.Infof("serve metrics: http://%s/metrics", l.Addr())
+	rec := metrics.NewRecorder()
+	onTask := c.OnTask
+	c.OnTask = func(task timeserver.Task) {
+		if onTask != nil {
+			onTask(task)
+		}
+		rec.TaskStarted(task)
+	}
+	c.Subscribers = append(c.Subscribers, rtn.Subscriber{Handler: rec})
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", rec)
+	srv := &http.Server{Handler: mux}
+	go func() {
+		if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+			log.Errorf("metrics: %v", err)
+		}
+	}()
+	return func() { srv.Close() }, nil
+}
+
+// validate checks numbers and durations of flags.
+func (e *flagPaser) validate() error {
+	for _, f := range []struct {
+		name  string
+		value int64
+	}{
+		{"sec", e.sec},
+		{"min", e.min},
+		{"hour", e.hour},
+		{"repeat", int64(e.repeat)},
+		{"log-max-size", e.logSize},
+		{"log-backups", int64(e.logKeep)},
+	} {
+		if f.value < 0 {
+			return fmt.Errorf("-%s %d %w", f.name, f.value, errNegativeFlag)
+		}
+	}
+	for _, f := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"tick", e.tick},
+		{"fade-in", e.fadeIn},
+		{"interval", e.interval},
+		{"max-duration", e.maxDur},
+	} {
+		if f.value < 0 {
+			return fmt.Errorf("-%s %s %w", f.name, f.value, errNegativeFlag)
+		}
+	}
+	for _, w := range e.warnings {
+		if w <= 0 {
+			return fmt.Errorf("-warnings %s %w", w, errNonPositiveArg)
+		}
+	}
+	if e.dryLoops <= 0 {
+		return fmt.Errorf("-dry-run-loops %d %w", e.dryLoops, errNonPositiveArg)
+	}
+	return nil
+}
+
+// setupLog sets default logger by log flags.
+func (e *flagPaser) setupLog() (close func() error, err error) {
+	level, err := log.ParseLevel(e.logLevel)
+	if err != nil {
+		return nil, err
+	}
+	if e.verbose {
+		level = log.DebugLevel
+	}
+	format := log.Format(e.logFmt)
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	if e.logFile == "" {
+		log.SetDefault(log.New(os.Stderr, level, format))
+		return func() error { return nil }, nil
+	}
+	f, err := log.OpenRotateFile(e.logFile, e.logSize<<20, e.logKeep)
+	if err != nil {
+		return nil, fmt.Errorf("log file: %w", err)
+	}
+	log.SetDefault(log.New(f, level, format))
+	return f.Close, nil
+}
+
+// run runs serve with stdin and stdout, or with terminal UI in tui mode.
+func (e *flagPaser) run(tasks []timeserver.Task, serve tui.ServeFunc) error {
+	if e.tui {
+		return tui.Run(tasks, serve)
+	}
+	return serve(os.Stdin, os.Stdout)
+}
+
 func main() {
 	parser := newParser()
 	if err := exec(parser, os.Args); err != nil {
@@ -53,11 +341,36 @@ func main() {
 }
 
 func exec(parser *flagPaser, args []string) error {
-	err := parser.parse(args[1:])
+	args = args[1:]
+	showConfig := len(args) > 0 && args[0] == "config"
+	if showConfig {
+		if len(args) < 2 || args[1] != "show" {
+			var sub string
+			if len(args) > 1 {
+				sub = args[1]
+			}
+			return fmt.Errorf("unknown config subcommand %q (want \"show\")", sub)
+		}
+		args = args[2:]
+	}
+	err := parser.parse(args)
+	if err != nil {
+		return err
+	}
+	if err := parser.loadConfig(); err != nil {
+		return err
+	}
+	if err := parser.validate(); err != nil {
+		return err
+	}
+	if showConfig {
+		return parser.showConfig(os.Stdout)
+	}
+	closeLog, err := parser.setupLog()
 	if err != nil {
 		return err
 	}
-	log.SetVerbose(parser.verbose)
+	defer closeLog()
 	// describe mode
 	if parser.describe != "" {
 		jw := json.NewEncoder(os.Stdout)
@@ -79,40 +392,93 @@ func exec(parser *flagPaser, args []string) error {
 		return nil
 	}
 
-	if parser.file == "" || parser.sec == 0 && parser.min == 0 && parser.hour == 0 && parser.time == "" && parser.routine == "" {
+	if parser.sec == 0 && parser.min == 0 && parser.hour == 0 && parser.time == "" && parser.routine == "" && parser.rtnFile == "" {
 		return fmt.Errorf("insufficient arguments")
 	}
 
-	if _, err := os.Stat(parser.file); os.IsNotExist(err) {
-		return err
+	if parser.file != "" && !strings.ContainsAny(parser.file, "*?[") {
+		if _, err := os.Stat(parser.file); os.IsNotExist(err) {
+			return err
+		}
 	}
 
 	// routine mode
-	if parser.routine != "" {
-		log.Printf("input routine: %s\n", parser.routine)
-		var rj []taskJson
-		err := json.Unmarshal([]byte(parser.routine), &rj)
+	if parser.routine != "" || parser.rtnFile != "" {
+		routine, err := parser.loadRoutine()
 		if err != nil {
+			return err
+		}
+		if err := routine.Validate(); err != nil {
 			return fmt.Errorf("parse routine: %w", err)
 		}
-		if err := rtn.RunRoutine(os.Stdin, os.Stdout, convertTask(rj), parser.file, parser.loop); err != nil {
+		if parser.dryRun {
+			return parser.printTimeline(os.Stdout, routine, time.Now())
+		}
+		tasks := make([]timeserver.Task, len(routine))
+		for i, step := range routine {
+			tasks[i] = step.Task
+		}
+		var load func() (rtn.Routine, error)
+		if parser.rtnFile != "" {
+			load = parser.loadRoutine
+		}
+		ctx, commands, reload, stop := notifySignals(context.Background(), load)
+		defer stop()
+		config := parser.config()
+		config.Commands = commands
+		config.Reload = reload
+		closeMetrics, err := parser.serveMetrics(&config)
+		if err != nil {
 			return err
 		}
-		return nil
+		defer closeMetrics()
+		return parser.run(tasks, func(r io.Reader, w io.Writer) error {
+			return stopped(ctx, rtn.RunRoutineContext(ctx, r, w, routine, config))
+		})
 	}
 
 	// alarm mode
 	var duration time.Duration
+	now := time.Now()
 	if parser.time != "" {
-		log.Printf("input time: %s\n", parser.time)
-		duration, err = timeParse(parser.time, time.Now())
+		log.Debugf("input time: %s", parser.time)
+		duration, err = timeParse(parser.time, now, parser.tomorrow)
 		if err != nil {
 			return fmt.Errorf("parse time arg: %w", err)
 		}
 	} else {
-		log.Printf("input hour(%d), min(%d), sec(%d)\n", parser.hour, parser.min, parser.sec)
+		log.Debugf("input hour(%d), min(%d), sec(%d)", parser.hour, parser.min, parser.sec)
 		duration = time.Hour*time.Duration(parser.hour) + time.Minute*time.Duration(parser.min) + time.Second*time.Duration(parser.sec)
 	}
 
-	return rtn.RunAlarm(os.Stdin, os.Stdout, duration, parser.file, parser.loop)
+	step := parser.step()
+	step.Range = duration
+	step.Name = "alarm"
+	if parser.dryRun {
+		routine := rtn.Routine{step}
+		if err := routine.Validate(); err != nil {
+			return err
+		}
+		return parser.printTimeline(os.Stdout, routine, now)
+	}
+	ctx, commands, _, stop := notifySignals(context.Background(), nil)
+	defer stop()
+	config := parser.config()
+	config.Commands = commands
+	closeMetrics, err := parser.serveMetrics(&config)
+	if err != nil {
+		return err
+	}
+	defer closeMetrics()
+	return parser.run([]timeserver.Task{step.Task}, func(r io.Reader, w io.Writer) error {
+		return stopped(ctx, rtn.RunAlarmContext(ctx, r, w, step, config))
+	})
+}
+
+// stopped returns nil when err is caused by stop signals, because stopping is not failure.
+func stopped(ctx context.Context, err error) error {
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return nil
+	}
+	return err
 }
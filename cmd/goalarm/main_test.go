@@ -78,8 +78,114 @@ func TestMain(t *testing.T) {
 			wantErr: "parse routine: invalid character ']' looking for beginning of value",
 		},
 		{
-			name:    "file empty",
-			args:    []string{"goalarm", "-sec", "10"},
+			name:    "bad tone",
+			args:    []string{"goalarm", "-tone", "880:200ms", "-sec", "10"},
+			wantErr: "'880:200ms' is bad tone format",
+		},
+		{
+			name:    "glob no match",
+			args:    []string{"goalarm", "-file", "nothing/*.mp3", "-sec", "10"},
+			wantErr: "nothing/*.mp3: no sound file",
+		},
+		{
+			name:    "bad order",
+			args:    []string{"goalarm", "-order", "shuffle", "-sec", "10"},
+			wantErr: "'shuffle' is bad order. support sequential or random",
+		},
+		{
+			name:    "bad volume",
+			args:    []string{"goalarm", "-volume", "loud", "-sec", "10"},
+			wantErr: "'loud' is bad volume format",
+		},
+		{
+			name: "bad routine sound",
+			args: []string{"goalarm", "-routine",
+				`[{"range":20,"name":"working","sound":{"fade_in":"slow"}}]`},
+			wantErr: `parse routine: time: invalid duration "slow"`,
+		},
+		{
+			name:    "routine file not found",
+			args:    []string{"goalarm", "-routine-file", "notfound.json"},
+			wantErr: "read routine: open notfound.json: no such file or directory",
+		},
+		{
+			name: "dry run",
+			args: []string{"goalarm", "-dry-run", "-silent", "-loop", "-routine",
+				`[{"range":20,"name":"working"},{"range":5,"name":"break"}]`},
+		},
+		{
+			name:    "dry run bad sound",
+			args:    []string{"goalarm", "-dry-run", "-file", "empty.aac", "-min", "5"},
+			file:    "empty.aac",
+			wantErr: "step 1(alarm): sound: open .aac: unsuported ext",
+		},
+		{
+			name: "zero range",
+			args: []string{"goalarm", "-routine",
+				`[{"range":20,"name":"working"},{"range":0,"name":"break"}]`},
+			wantErr: "parse routine: step 2(break): 0s: range must be positive",
+		},
+		{
+			name: "duplicate index",
+			args: []string{"goalarm", "-routine",
+				`[{"index":1,"range":20,"name":"working"},{"index":1,"range":5,"name":"break"}]`},
+			wantErr: "parse routine: step 2(break): index 1 of step 1: index is duplicated",
+		},
+		{
+			name:    "past time",
+			args:    []string{"goalarm", "-time", "00:00:00"},
+			wantErr: "parse time arg: 00:00:00 is in the past; use -tomorrow or a date",
+		},
+		{
+			name:    "negative min",
+			args:    []string{"goalarm", "-min", "-5"},
+			wantErr: "-min -5 must not be negative",
+		},
+		{
+			name:    "negative tick",
+			args:    []string{"goalarm", "-min", "5", "-tick", "-1s"},
+			wantErr: "-tick -1s must not be negative",
+		},
+		{
+			name:    "zero warning",
+			args:    []string{"goalarm", "-min", "5", "-warnings", "1m,0s"},
+			wantErr: "-warnings 0s must be positive",
+		},
+		{
+			name: "negative routine warning",
+			args: []string{"goalarm", "-routine",
+				`[{"range":20,"name":"working","warnings":["-1m"]}]`},
+			wantErr: "parse routine: step 1(working): -1m0s: warning must be positive",
+		},
+		{
+			name: "negative routine sound",
+			args: []string{"goalarm", "-dry-run", "-routine",
+				`[{"range":20,"name":"working","sound":{"interval":"-2s"}}]`},
+			wantErr: "step 1(working): sound: interval -2s must not be negative",
+		},
+		{
+			name:    "config routine not found",
+			args:    []string{"goalarm", "-routine", "pomodoro"},
+			wantErr: "read routine: 'pomodoro' is not in config routines",
+		},
+		{
+			name:    "config without show",
+			args:    []string{"goalarm", "config", "edit"},
+			wantErr: `unknown config subcommand "edit" (want "show")`,
+		},
+		{
+			name:    "bad log level",
+			args:    []string{"goalarm", "-log-level", "trace", "-sec", "10"},
+			wantErr: "'trace' is unknown log level",
+		},
+		{
+			name:    "bad log format",
+			args:    []string{"goalarm", "-log-format", "xml", "-sec", "10"},
+			wantErr: "'xml' is unknown log format",
+		},
+		{
+			name:    "tone only",
+			args:    []string{"goalarm", "-tone", "880hz:200ms"},
 			wantErr: "insufficient arguments",
 		},
 		{
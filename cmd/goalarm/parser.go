@@ -1,18 +1,133 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	rtn "github.com/komem3/goalarm/internal/routine"
+	"github.com/komem3/goalarm/internal/sound"
 	"github.com/komem3/goalarm/internal/timeserver"
 )
 
 type taskJson struct {
-	Index int           `json:"index"`
-	Range time.Duration `json:"range"`
-	Name  string        `json:"name"`
+	Index        int            `json:"index"`
+	Range        rangeJson      `json:"range"`
+	Until        *clockJson     `json:"until"`
+	Name         string         `json:"name"`
+	Sound        soundJson      `json:"sound"`
+	Warnings     []durationJson `json:"warnings"`
+	WarningSound soundJson      `json:"warning_sound"`
+}
+
+type soundJson struct {
+	Files       []string     `json:"files"`
+	Order       string       `json:"order"`
+	Tone        string       `json:"tone"`
+	Volume      string       `json:"volume"`
+	FadeIn      durationJson `json:"fade_in"`
+	Repeat      int          `json:"repeat"`
+	Interval    durationJson `json:"interval"`
+	MaxDuration durationJson `json:"max_duration"`
+}
+
+// durationJson is duration string of json.("1m30s")
+type durationJson time.Duration
+
+func (d *durationJson) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationJson(duration)
+	return nil
+}
+
+// rangeJson is minutes(20) or duration string("1h30m") of json.
+// Number string("20") is minutes too, so that routine template can use it.
+type rangeJson time.Duration
+
+func (r *rangeJson) UnmarshalJSON(b []byte) error {
+	var minutes float64
+	if err := json.Unmarshal(b, &minutes); err == nil {
+		*r = rangeJson(minutes * float64(time.Minute))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if minutes, err := strconv.ParseFloat(s, 64); err == nil {
+		*r = rangeJson(minutes * float64(time.Minute))
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*r = rangeJson(d)
+	return nil
+}
+
+// clockJson is clock string of json.("10:30")
+type clockJson rtn.Clock
+
+func (c *clockJson) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	clock, err := rtn.ParseClock(s)
+	if err != nil {
+		return err
+	}
+	*c = clockJson(clock)
+	return nil
+}
+
+func (s soundJson) config() sound.Config {
+	return sound.Config{
+		Files: s.Files,
+		Order: sound.Order(s.Order),
+		Tone:  s.Tone,
+		Effect: sound.Effect{
+			Volume:      s.Volume,
+			FadeIn:      time.Duration(s.FadeIn),
+			Repeat:      s.Repeat,
+			Interval:    time.Duration(s.Interval),
+			MaxDuration: time.Duration(s.MaxDuration),
+		},
+	}
+}
+
+// durationsFlag is comma separated durations.("1m,10s")
+type durationsFlag []time.Duration
+
+func (d *durationsFlag) String() string {
+	strs := make([]string, len(*d))
+	for i, duration := range *d {
+		strs[i] = duration.String()
+	}
+	return strings.Join(strs, ",")
+}
+
+func (d *durationsFlag) Set(s string) error {
+	*d = nil
+	for _, str := range strings.Split(s, ",") {
+		duration, err := time.ParseDuration(strings.TrimSpace(str))
+		if err != nil {
+			return err
+		}
+		*d = append(*d, duration)
+	}
+	return nil
 }
 
 type timeParser struct {
@@ -23,46 +138,94 @@ type timeParser struct {
 }
 
 func (t *timeParser) setHour(h string) *timeParser {
-	if t.err != nil {
-		return t
-	}
-	t.hour, t.err = strconv.Atoi(h)
+	t.hour = t.part(h, "hour", 23)
 	return t
 }
 
 func (t *timeParser) setMin(m string) *timeParser {
-	if t.err != nil {
-		return t
-	}
-	t.min, t.err = strconv.Atoi(m)
+	t.min = t.part(m, "minute", 59)
 	return t
 }
 
 func (t *timeParser) setSec(s string) *timeParser {
+	t.sec = t.part(s, "second", 59)
+	return t
+}
+
+// part parses s of clock, which must be from 0 to max.
+func (t *timeParser) part(s, name string, max int) int {
 	if t.err != nil {
-		return t
+		return 0
 	}
-	t.sec, t.err = strconv.Atoi(s)
-	return t
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		t.err = err
+		return 0
+	}
+	if n < 0 || n > max {
+		t.err = fmt.Errorf("%s %d %w(0-%d)", name, n, errClockRange, max)
+		return 0
+	}
+	return n
 }
 
 func (t *timeParser) time(now time.Time) time.Time {
 	return time.Date(now.Year(), now.Month(), now.Day(), t.hour, t.min, t.sec, 0, time.Local)
 }
 
-func convertTask(tasks []taskJson) rtn.Routine {
+// convertTask converts json to routine.
+// Warnings and Warning of base are used when task does not have them.
+func convertTask(tasks []taskJson, base rtn.Step) rtn.Routine {
 	r := make(rtn.Routine, 0, len(tasks))
 	for _, t := range tasks {
-		r = append(r, timeserver.Task{
-			Index: t.Index,
-			Range: time.Minute * t.Range,
-			Name:  t.Name,
+		warnings := base.Warnings
+		if t.Warnings != nil {
+			warnings = make([]time.Duration, len(t.Warnings))
+			for i, w := range t.Warnings {
+				warnings[i] = time.Duration(w)
+			}
+		}
+		r = append(r, rtn.Step{
+			Task: timeserver.Task{
+				Index:    t.Index,
+				Range:    time.Duration(t.Range),
+				Name:     t.Name,
+				Warnings: warnings,
+			},
+			Sound:   t.Sound.config(),
+			Warning: base.Warning.Override(t.WarningSound.config()),
+			Until:   (*rtn.Clock)(t.Until),
 		})
 	}
 	return r
 }
 
-func timeParse(tstr string, now time.Time) (d time.Duration, err error) {
+var (
+	errPastTime       = errors.New("is in the past; use -tomorrow or a date")
+	errTomorrowDate   = errors.New("-tomorrow can not be used with a date")
+	errNegativeFlag   = errors.New("must not be negative")
+	errNonPositiveArg = errors.New("must be positive")
+	errClockRange     = errors.New("is out of range")
+)
+
+// dateLayouts are layouts of -time with date.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// timeParse returns duration from now to tstr.("15:00:01" or "2006-01-02 15:00")
+// Clock time is today, or tomorrow when tomorrow is true. The time must be after now.
+func timeParse(tstr string, now time.Time, tomorrow bool) (d time.Duration, err error) {
+	if strings.Contains(tstr, "-") {
+		if tomorrow {
+			return 0, errTomorrowDate
+		}
+		return dateParse(tstr, now)
+	}
 	times := strings.Split(tstr, ":")
 	parser := &timeParser{
 		hour: now.Hour(),
@@ -82,5 +245,27 @@ func timeParse(tstr string, now time.Time) (d time.Duration, err error) {
 		return 0, parser.err
 	}
 
-	return parser.time(now).Sub(now), parser.err
+	t := parser.time(now)
+	if tomorrow {
+		t = t.AddDate(0, 0, 1)
+	}
+	if !t.After(now) {
+		return 0, fmt.Errorf("%s %w", tstr, errPastTime)
+	}
+	return t.Sub(now), nil
+}
+
+func dateParse(tstr string, now time.Time) (d time.Duration, err error) {
+	for _, layout := range dateLayouts {
+		t, perr := time.ParseInLocation(layout, tstr, time.Local)
+		if perr != nil {
+			err = perr
+			continue
+		}
+		if !t.After(now) {
+			return 0, fmt.Errorf("%s %w", tstr, errPastTime)
+		}
+		return t.Sub(now), nil
+	}
+	return 0, err
 }
@@ -1,51 +1,109 @@
 package main
 
 import (
+	"encoding/json"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	rtn "github.com/komem3/goalarm/internal/routine"
+	"github.com/komem3/goalarm/internal/sound"
+	"github.com/komem3/goalarm/internal/timeserver"
 )
 
 func TestTimeParse(t *testing.T) {
+	type given struct {
+		time     string
+		tomorrow bool
+	}
 	type want struct {
 		d   time.Duration
 		err error
 	}
 	tests := []struct {
 		name  string
-		given string
+		given given
 		want  want
 	}{
 		{
 			"hour only",
-			"16",
+			given{"16", false},
 			want{time.Hour, nil},
 		},
 		{
 			"hour + minute",
-			"15:01",
+			given{"15:01", false},
 			want{time.Minute, nil},
 		},
 		{
 			"hour + minute + sec",
-			"15:00:01",
+			given{"15:00:01", false},
 			want{time.Second, nil},
 		},
 		{
 			"bad hour",
-			"bad",
+			given{"bad", false},
 			want{0, strconv.ErrSyntax},
 		},
+		{
+			"out of range hour",
+			given{"25:00", false},
+			want{0, errClockRange},
+		},
+		{
+			"out of range minute",
+			given{"10:75", true},
+			want{0, errClockRange},
+		},
+		{
+			"out of range second",
+			given{"15:00:60", false},
+			want{0, errClockRange},
+		},
+		{
+			"past",
+			given{"14:59:59", false},
+			want{0, errPastTime},
+		},
+		{
+			"now",
+			given{"15:00:00", false},
+			want{0, errPastTime},
+		},
+		{
+			"tomorrow",
+			given{"14:00", true},
+			want{time.Hour * 23, nil},
+		},
+		{
+			"date",
+			given{"2010-01-02 15:00", false},
+			want{time.Hour * 24, nil},
+		},
+		{
+			"date with T",
+			given{"2010-01-01T15:30:00", false},
+			want{time.Minute * 30, nil},
+		},
+		{
+			"past date",
+			given{"2009-12-31 15:00", false},
+			want{0, errPastTime},
+		},
+		{
+			"tomorrow with date",
+			given{"2010-01-02 15:00", true},
+			want{0, errTomorrowDate},
+		},
 	}
 	now := time.Date(2010, 1, 1, 15, 0, 0, 0, time.Local)
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			d, err := timeParse(tt.given, now)
+			d, err := timeParse(tt.given.time, now, tt.given.tomorrow)
 			if diff := cmp.Diff(d, tt.want.d); diff != "" {
 				t.Errorf("timeParse duration, given(+), want(-)\n%s\n", diff)
 			}
@@ -55,3 +113,38 @@ func TestTimeParse(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertTask_Warnings(t *testing.T) {
+	var tasks []taskJson
+	if err := json.Unmarshal([]byte(`[
+		{"range":20,"name":"working"},
+		{"range":5,"name":"break","warnings":["30s"],"warning_sound":{"tone":"440hz:100ms"}}
+	]`), &tasks); err != nil {
+		t.Fatal(err)
+	}
+	base := rtn.Step{
+		Task:    timeserver.Task{Warnings: []time.Duration{time.Minute}},
+		Warning: sound.Config{Files: []string{"warning.mp3"}},
+	}
+	want := rtn.Routine{
+		{
+			Task: timeserver.Task{
+				Range:    20 * time.Minute,
+				Name:     "working",
+				Warnings: []time.Duration{time.Minute},
+			},
+			Warning: sound.Config{Files: []string{"warning.mp3"}},
+		},
+		{
+			Task: timeserver.Task{
+				Range:    5 * time.Minute,
+				Name:     "break",
+				Warnings: []time.Duration{30 * time.Second},
+			},
+			Warning: sound.Config{Tone: "440hz:100ms"},
+		},
+	}
+	if diff := cmp.Diff(convertTask(tasks, base), want); diff != "" {
+		t.Errorf("convertTask, given(-), want(+)\n%s\n", diff)
+	}
+}
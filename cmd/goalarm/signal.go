@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/komem3/goalarm/internal/log"
+	rtn "github.com/komem3/goalarm/internal/routine"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+// notifySignals converts signals to commands of timer.
+// Reload signals send the routine loaded by load. Nil load ignores them.
+// Stop signals cancel the returned context, so that they stop the timer and the playing sound at any time.
+// Stop signals are no longer caught after that, so the second one terminates the process.
+func notifySignals(ctx context.Context, load func() (rtn.Routine, error)) (context.Context, <-chan timeserver.Command, <-chan rtn.Routine, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	signals := append([]os.Signal(nil), reloadSignals...)
+	for sig := range signalCommands {
+		signals = append(signals, sig)
+	}
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, signals...)
+	stops := make(chan os.Signal, 1)
+	signal.Notify(stops, stopSignals...)
+
+	commands := make(chan timeserver.Command)
+	routines := make(chan rtn.Routine, 1)
+	done := make(chan struct{})
+	go func() {
+		for {
+			var sig os.Signal
+			select {
+			case <-done:
+				return
+			case sig = <-stops:
+				log.Infof("receive signal: %s", sig)
+				signal.Stop(stops)
+				cancel()
+				continue
+			case sig = <-sigs:
+			}
+			log.Infof("receive signal: %s", sig)
+			if cmd, ok := signalCommands[sig]; ok {
+				select {
+				case commands <- cmd:
+				case <-done:
+					return
+				}
+				continue
+			}
+			if load == nil {
+				continue
+			}
+			routine, err := load()
+			if err != nil {
+				log.Warnf("reload: %v", err)
+				continue
+			}
+			// the latest routine replaces the one not applied yet.
+			select {
+			case <-routines:
+			default:
+			}
+			routines <- routine
+		}
+	}()
+	return ctx, commands, routines, func() {
+		signal.Stop(sigs)
+		signal.Stop(stops)
+		close(done)
+		cancel()
+	}
+}
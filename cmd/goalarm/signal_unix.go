@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+var signalCommands = map[os.Signal]timeserver.Command{
+	syscall.SIGUSR1: timeserver.ToggleCommand,
+	syscall.SIGUSR2: timeserver.GetCommand,
+}
+
+var stopSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+
+var reloadSignals = []os.Signal{syscall.SIGHUP}
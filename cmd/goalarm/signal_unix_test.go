@@ -0,0 +1,34 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNotifySignals_Stop(t *testing.T) {
+	ctx, _, _, stop := notifySignals(context.Background(), nil)
+	defer stop()
+
+	// the final sound waits ctx without reading commands.
+	played := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(played)
+	}()
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-played:
+	case <-time.After(time.Second):
+		t.Fatal("SIGINT does not stop playing sound")
+	}
+	if err := stopped(ctx, ctx.Err()); err != nil {
+		t.Errorf("stopped: given %v, want nil", err)
+	}
+}
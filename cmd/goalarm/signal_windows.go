@@ -0,0 +1,13 @@
+package main
+
+import (
+	"os"
+
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+var signalCommands = map[os.Signal]timeserver.Command{}
+
+var stopSignals = []os.Signal{os.Interrupt}
+
+var reloadSignals []os.Signal
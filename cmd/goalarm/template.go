@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	errUnknownVar = errors.New("unknown variable")
+	errMissingVar = errors.New("missing variable")
+	errBadSet     = errors.New("bad -set format. use key=value")
+)
+
+// varPattern is the variable of routine template.({{work}})
+var varPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// routineTemplate is routine json with params.
+// Params are defaults of variables. Null param has no default and must be set by -set.
+//
+//	{"params":{"work":"25m","break":"5m"},"routine":[{"range":"{{work}}","name":"working"},{"range":"{{break}}","name":"break"}]}
+type routineTemplate struct {
+	Params  map[string]*string `json:"params"`
+	Routine json.RawMessage    `json:"routine"`
+}
+
+// setFlag is variables of routine template.("work=50m")
+type setFlag map[string]string
+
+func (s *setFlag) String() string {
+	strs := make([]string, 0, len(*s))
+	for k, v := range *s {
+		strs = append(strs, k+"="+v)
+	}
+	sort.Strings(strs)
+	return strings.Join(strs, ",")
+}
+
+func (s *setFlag) Set(v string) error {
+	kv := strings.SplitN(v, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return fmt.Errorf("'%s' is %w", v, errBadSet)
+	}
+	if *s == nil {
+		*s = make(setFlag)
+	}
+	(*s)[kv[0]] = kv[1]
+	return nil
+}
+
+// expandRoutine returns routine json array of b.
+// Variables in string values of template are replaced by values or defaults of params.
+func expandRoutine(b []byte, values map[string]string) ([]byte, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		if m := varPattern.FindSubmatch(b); m != nil {
+			return nil, fmt.Errorf("{{%s}}: %w. routine needs params", m[1], errUnknownVar)
+		}
+		if names := sortedKeys(values); len(names) > 0 {
+			return nil, fmt.Errorf("-set %s: %w", names[0], errUnknownVar)
+		}
+		return b, nil
+	}
+
+	var t routineTemplate
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, err
+	}
+	for _, name := range sortedKeys(values) {
+		if _, ok := t.Params[name]; !ok {
+			return nil, fmt.Errorf("-set %s: %w", name, errUnknownVar)
+		}
+	}
+	vars := make(map[string]string, len(t.Params))
+	for name, def := range t.Params {
+		if v, ok := values[name]; ok {
+			vars[name] = v
+		} else if def != nil {
+			vars[name] = *def
+		}
+	}
+
+	var routine interface{}
+	if err := json.Unmarshal(t.Routine, &routine); err != nil {
+		return nil, err
+	}
+	routine, err := expandValue(routine, vars, t.Params)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(routine)
+}
+
+// expandValue replaces variables in strings of v.
+func expandValue(v interface{}, vars map[string]string, params map[string]*string) (interface{}, error) {
+	var err error
+	switch v := v.(type) {
+	case string:
+		s := varPattern.ReplaceAllStringFunc(v, func(m string) string {
+			name := varPattern.FindStringSubmatch(m)[1]
+			value, ok := vars[name]
+			switch {
+			case err != nil:
+			case !hasKey(params, name):
+				err = fmt.Errorf("{{%s}}: %w", name, errUnknownVar)
+			case !ok:
+				err = fmt.Errorf("{{%s}}: %w. use -set %s=value", name, errMissingVar, name)
+			}
+			return value
+		})
+		return s, err
+	case []interface{}:
+		for i := range v {
+			if v[i], err = expandValue(v[i], vars, params); err != nil {
+				return nil, err
+			}
+		}
+	case map[string]interface{}:
+		// sorted keys report the same error every time.
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if v[k], err = expandValue(v[k], vars, params); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+
+func hasKey(params map[string]*string, name string) bool {
+	_, ok := params[name]
+	return ok
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExpandRoutine(t *testing.T) {
+	const template = `{
+		"params": {"work": "25m", "break": "5", "name": null},
+		"routine": [
+			{"range": "{{work}}", "name": "{{ name }} working", "warnings": ["1m"]},
+			{"range": "{{break}}", "name": "break"}
+		]
+	}`
+	type given struct {
+		routine string
+		values  map[string]string
+	}
+	type want struct {
+		ranges  []time.Duration
+		names   []string
+		wantErr error
+	}
+	tests := []struct {
+		name  string
+		given given
+		want  want
+	}{
+		{
+			"defaults",
+			given{template, map[string]string{"name": "sprint"}},
+			want{[]time.Duration{time.Minute * 25, time.Minute * 5}, []string{"sprint working", "break"}, nil},
+		},
+		{
+			"set",
+			given{template, map[string]string{"name": "sprint", "work": "50m", "break": "1h"}},
+			want{[]time.Duration{time.Minute * 50, time.Hour}, []string{"sprint working", "break"}, nil},
+		},
+		{
+			"missing variable",
+			given{template, nil},
+			want{nil, nil, errMissingVar},
+		},
+		{
+			"unknown set",
+			given{template, map[string]string{"name": "sprint", "rest": "10m"}},
+			want{nil, nil, errUnknownVar},
+		},
+		{
+			"unknown variable",
+			given{`{"params": {}, "routine": [{"range": "{{work}}"}]}`, nil},
+			want{nil, nil, errUnknownVar},
+		},
+		{
+			"set without params",
+			given{`[{"range": 20, "name": "working"}]`, map[string]string{"work": "50m"}},
+			want{nil, nil, errUnknownVar},
+		},
+		{
+			"array",
+			given{`[{"range": 20, "name": "working"}]`, nil},
+			want{[]time.Duration{time.Minute * 20}, []string{"working"}, nil},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			parser := newParser()
+			parser.routine = tt.given.routine
+			parser.vars = tt.given.values
+			routine, err := parser.loadRoutine()
+			if !errors.Is(err, tt.want.wantErr) {
+				t.Fatalf("given %v, want %v", err, tt.want.wantErr)
+			}
+			var (
+				ranges []time.Duration
+				names  []string
+			)
+			for _, step := range routine {
+				ranges = append(ranges, step.Range)
+				names = append(names, step.Name)
+			}
+			if diff := cmp.Diff(ranges, tt.want.ranges); diff != "" {
+				t.Errorf("ranges: given(-), want(+)\n%s\n", diff)
+			}
+			if diff := cmp.Diff(names, tt.want.names); diff != "" {
+				t.Errorf("names: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestExpandRoutine_ErrorOrder(t *testing.T) {
+	given := `{"params": {"a": null, "b": null}, "routine": [{"range": "{{b}}", "name": "{{a}}", "warnings": ["{{b}}"]}]}`
+	for i := 0; i < 20; i++ {
+		_, err := expandRoutine([]byte(given), nil)
+		if diff := cmp.Diff(err.Error(), "{{a}}: missing variable. use -set a=value"); diff != "" {
+			t.Fatalf("error: given(-), want(+)\n%s\n", diff)
+		}
+	}
+}
+
+func TestSetFlag(t *testing.T) {
+	var s setFlag
+	for _, v := range []string{"work=50m", "name=a=b"} {
+		if err := s.Set(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if diff := cmp.Diff(s.String(), "name=a=b,work=50m"); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+	if err := s.Set("work"); !errors.Is(err, errBadSet) {
+		t.Errorf("given %v, want %v", err, errBadSet)
+	}
+}
@@ -3,7 +3,9 @@ module github.com/komem3/goalarm
 go 1.15
 
 require (
+	github.com/BurntSushi/toml v0.3.0
 	github.com/faiface/beep v1.0.2
+	github.com/gdamore/tcell v1.1.1
 	github.com/google/go-cmp v0.5.3
 	golang.org/x/sys v0.0.0-20200930185726-fdedc70b468f // indirect
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
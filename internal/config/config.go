@@ -0,0 +1,213 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+// EnvPrefix is the prefix of environment variables overriding config. e.g. GOALARM_VOLUME
+const EnvPrefix = "GOALARM_"
+
+var (
+	ErrUnknownKey     = errors.New("unknown key")
+	ErrUnknownRoutine = errors.New("is not in config routines")
+	ErrBadHook        = errors.New("is not hook status")
+	ErrBadRoutine     = errors.New("is not array of steps or routine template")
+)
+
+// Config is default settings of flags, named routines and hooks.
+type Config struct {
+	Sound   Sound   `toml:"sound"`
+	Output  Output  `toml:"output"`
+	Command Command `toml:"command"`
+	Log     Log     `toml:"log"`
+	Metrics Metrics `toml:"metrics"`
+	// Routines are routines by name. A routine is array of steps same as routine json,
+	// or routine template table having params and routine.
+	Routines map[string]interface{} `toml:"routines"`
+	// Hooks are shell commands run at the status.
+	Hooks map[string]string `toml:"hooks"`
+}
+
+type Sound struct {
+	File     string `toml:"file,omitempty"`
+	Order    string `toml:"order,omitempty"`
+	Tone     string `toml:"tone,omitempty"`
+	Silent   bool   `toml:"silent,omitempty"`
+	Announce string `toml:"announce,omitempty"`
+	Volume   string `toml:"volume,omitempty"`
+	FadeIn   string `toml:"fade_in,omitempty"`
+	Repeat   int    `toml:"repeat,omitzero"`
+	Interval string `toml:"interval,omitempty"`
+	// MaxDuration is max play duration of sound.
+	MaxDuration string `toml:"max_duration,omitempty"`
+}
+
+type Output struct {
+	Format   string `toml:"format,omitempty"`
+	Template string `toml:"template,omitempty"`
+	Tick     string `toml:"tick,omitempty"`
+}
+
+type Command struct {
+	Strict bool `toml:"strict,omitempty"`
+	// OnEOF is behavior when stdin is closed.
+	OnEOF string `toml:"on_eof,omitempty"`
+}
+
+type Log struct {
+	Level  string `toml:"level,omitempty"`
+	Format string `toml:"format,omitempty"`
+	File   string `toml:"file,omitempty"`
+	// MaxSize is megabytes of log file before rotation.
+	MaxSize int64 `toml:"max_size,omitzero"`
+	Backups int   `toml:"backups,omitzero"`
+}
+
+type Metrics struct {
+	Addr string `toml:"addr,omitempty"`
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/goalarm/config.toml.
+// It is ~/.config/goalarm/config.toml when XDG_CONFIG_HOME is empty.
+func DefaultPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "goalarm", "config.toml"), nil
+}
+
+// Load reads config of path. Empty config is returned when optional is true and path does not exist.
+func Load(path string, optional bool) (Config, error) {
+	var c Config
+	md, err := toml.DecodeFile(path, &c)
+	if err != nil {
+		if optional && os.IsNotExist(err) {
+			return Config{}, nil
+		}
+		return Config{}, fmt.Errorf("config: %w", err)
+	}
+	for _, key := range md.Undecoded() {
+		// routines are decoded as interface{} and checked by the routine parser.
+		if key[0] == "routines" {
+			continue
+		}
+		return Config{}, fmt.Errorf("config: %s is %w", key, ErrUnknownKey)
+	}
+	if err := c.validateHooks(); err != nil {
+		return Config{}, fmt.Errorf("config: %w", err)
+	}
+	if err := c.validateRoutines(); err != nil {
+		return Config{}, fmt.Errorf("config: %w", err)
+	}
+	return c, nil
+}
+
+func (c Config) validateHooks() error {
+	statuses := make(map[timeserver.Status]bool)
+	for _, s := range timeserver.AllStatuses() {
+		statuses[s.Status] = true
+	}
+	for status := range c.Hooks {
+		if !statuses[timeserver.Status(status)] {
+			return fmt.Errorf("'%s' %w", status, ErrBadHook)
+		}
+	}
+	return nil
+}
+
+func (c Config) validateRoutines() error {
+	for name, r := range c.Routines {
+		switch r.(type) {
+		case []map[string]interface{}, map[string]interface{}:
+		default:
+			return fmt.Errorf("routines.%s %w", name, ErrBadRoutine)
+		}
+	}
+	return nil
+}
+
+// Flags returns values of flags by flag name. Empty values are not included.
+func (c Config) Flags() map[string]string {
+	flags := make(map[string]string)
+	set := func(name, value string) {
+		if value != "" {
+			flags[name] = value
+		}
+	}
+	set("file", c.Sound.File)
+	set("order", c.Sound.Order)
+	set("tone", c.Sound.Tone)
+	if c.Sound.Silent {
+		set("silent", "true")
+	}
+	set("announce", c.Sound.Announce)
+	set("volume", c.Sound.Volume)
+	set("fade-in", c.Sound.FadeIn)
+	if c.Sound.Repeat != 0 {
+		set("repeat", strconv.Itoa(c.Sound.Repeat))
+	}
+	set("interval", c.Sound.Interval)
+	set("max-duration", c.Sound.MaxDuration)
+	set("output-format", c.Output.Format)
+	set("format", c.Output.Template)
+	set("tick", c.Output.Tick)
+	if c.Command.Strict {
+		set("strict", "true")
+	}
+	set("on-eof", c.Command.OnEOF)
+	set("log-level", c.Log.Level)
+	set("log-format", c.Log.Format)
+	set("log-file", c.Log.File)
+	if c.Log.MaxSize != 0 {
+		set("log-max-size", strconv.FormatInt(c.Log.MaxSize, 10))
+	}
+	if c.Log.Backups != 0 {
+		set("log-backups", strconv.Itoa(c.Log.Backups))
+	}
+	set("metrics-addr", c.Metrics.Addr)
+	return flags
+}
+
+// Routine returns the named routine. It is []map[string]interface{} of steps or map[string]interface{} of template.
+func (c Config) Routine(name string) (interface{}, error) {
+	r, ok := c.Routines[name]
+	if !ok {
+		return nil, fmt.Errorf("'%s' %w", name, ErrUnknownRoutine)
+	}
+	return r, nil
+}
+
+// HookStatuses returns statuses having hook in order of statuses.
+func (c Config) HookStatuses() []timeserver.Status {
+	var statuses []timeserver.Status
+	for _, s := range timeserver.AllStatuses() {
+		if _, ok := c.Hooks[string(s.Status)]; ok {
+			statuses = append(statuses, s.Status)
+		}
+	}
+	return statuses
+}
+
+// EnvName returns the environment variable of flag. e.g. output-format is GOALARM_OUTPUT_FORMAT
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// Write writes c as toml.
+func (c Config) Write(w io.Writer) error {
+	return toml.NewEncoder(w).Encode(c)
+}
@@ -0,0 +1,166 @@
+package config_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/komem3/goalarm/internal/config"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+const configToml = `
+[sound]
+file = "./bell.mp3"
+volume = "50%"
+repeat = 2
+
+[output]
+format = "text"
+tick = "1s"
+
+[command]
+strict = true
+on_eof = "continue"
+
+[log]
+level = "debug"
+max_size = 5
+
+[metrics]
+addr = "localhost:9090"
+
+[[routines.pomodoro]]
+range = 25
+name = "working"
+warnings = ["1m"]
+
+[[routines.pomodoro]]
+range = 5
+name = "break"
+
+[routines.custom]
+params = { work = "25m" }
+
+[[routines.custom.routine]]
+range = "{{work}}"
+name = "working"
+
+[hooks]
+finish = "notify-send goalarm finish"
+`
+
+func writeConfig(t *testing.T, text string) string {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := ioutil.WriteFile(path, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	c, err := config.Load(writeConfig(t, configToml), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(c.Flags(), map[string]string{
+		"file":          "./bell.mp3",
+		"volume":        "50%",
+		"repeat":        "2",
+		"output-format": "text",
+		"tick":          "1s",
+		"strict":        "true",
+		"on-eof":        "continue",
+		"log-level":     "debug",
+		"log-max-size":  "5",
+		"metrics-addr":  "localhost:9090",
+	}); diff != "" {
+		t.Errorf("flags: given(-), want(+)\n%s\n", diff)
+	}
+
+	routine, err := c.Routine("pomodoro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(routine, []map[string]interface{}{
+		{"range": int64(25), "name": "working", "warnings": []interface{}{"1m"}},
+		{"range": int64(5), "name": "break"},
+	}); diff != "" {
+		t.Errorf("routine: given(-), want(+)\n%s\n", diff)
+	}
+	template, err := c.Routine("custom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(template, map[string]interface{}{
+		"params":  map[string]interface{}{"work": "25m"},
+		"routine": []map[string]interface{}{{"range": "{{work}}", "name": "working"}},
+	}); diff != "" {
+		t.Errorf("template: given(-), want(+)\n%s\n", diff)
+	}
+	if _, err := c.Routine("nothing"); !errors.Is(err, config.ErrUnknownRoutine) {
+		t.Errorf("given %v, want %v", err, config.ErrUnknownRoutine)
+	}
+
+	if diff := cmp.Diff(c.HookStatuses(), []timeserver.Status{timeserver.FinishStatus}); diff != "" {
+		t.Errorf("hooks: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestLoad_Error(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr error
+	}{
+		{
+			name:    "unknown key",
+			text:    "[sound]\nvolum = \"50%\"\n",
+			wantErr: config.ErrUnknownKey,
+		},
+		{
+			name:    "bad hook",
+			text:    "[hooks]\nend = \"echo end\"\n",
+			wantErr: config.ErrBadHook,
+		},
+		{
+			name:    "bad routine",
+			text:    "[routines]\npomodoro = 25\n",
+			wantErr: config.ErrBadRoutine,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := config.Load(writeConfig(t, tt.text), false)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("given %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_NotExist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	c, err := config.Load(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Flags()) != 0 {
+		t.Errorf("optional config should be empty: %v", c.Flags())
+	}
+	if _, err := config.Load(path, false); err == nil || !strings.Contains(err.Error(), "no such file") {
+		t.Errorf("given %v, want not exist error", err)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if diff := cmp.Diff(config.EnvName("output-format"), "GOALARM_OUTPUT_FORMAT"); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+}
@@ -0,0 +1,125 @@
+package hook
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/komem3/goalarm/internal/log"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+// Command is the handler running shell command with the result.
+// The result is passed by environment variables GOALARM_STATUS, GOALARM_LEFT,
+// GOALARM_TASK, GOALARM_INDEX and GOALARM_ERROR.
+// The command runs in background not to delay the next task.
+// Output of the command is logged, so that it does not mix with results.
+type Command struct {
+	command string
+}
+
+func New(command string) *Command {
+	return &Command{command: command}
+}
+
+func (c *Command) Serve(r timeserver.Result) {
+	cmd := shell(c.command)
+	cmd.Env = append(os.Environ(), env(r)...)
+	logger := log.With("hook", c.command, "status", r.Status)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Start(); err != nil {
+		logger.Warnf("run hook: %v", err)
+		return
+	}
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			logger.Warnf("run hook: %v: %s", err, out.Bytes())
+			return
+		}
+		logger.Debugf("run hook: %s", out.Bytes())
+	}()
+}
+
+// Hooks dispatches results to handlers by status.
+// Handlers of states(running, pause, stop and finish) are called only when the state changes,
+// and the running handler is also called when a task starts.
+// Handlers of warning and error are called by every result.
+type Hooks struct {
+	handlers map[timeserver.Status]timeserver.Handler
+
+	mu    sync.Mutex
+	task  timeserver.Task
+	state timeserver.Status
+}
+
+func NewHooks(handlers map[timeserver.Status]timeserver.Handler) *Hooks {
+	return &Hooks{handlers: handlers}
+}
+
+// Start calls the running handler with the result of task which has just started.
+func (h *Hooks) Start(task timeserver.Task) {
+	h.mu.Lock()
+	h.task = task
+	h.state = timeserver.RunningStatus
+	h.mu.Unlock()
+	h.call(timeserver.Result{
+		Status:   timeserver.RunningStatus,
+		Left:     task.Range.Round(time.Second).String(),
+		LeftTime: task.Range,
+		Task:     task,
+	})
+}
+
+func (h *Hooks) Serve(r timeserver.Result) {
+	switch r.Status {
+	case timeserver.WarningStatus, timeserver.ErrorStatus:
+		h.call(r)
+		return
+	}
+	h.mu.Lock()
+	changed := h.state != r.Status || !sameTask(h.task, r.Task)
+	h.task = r.Task
+	h.state = r.Status
+	h.mu.Unlock()
+	if changed {
+		h.call(r)
+	}
+}
+
+func (h *Hooks) call(r timeserver.Result) {
+	if handler, ok := h.handlers[r.Status]; ok {
+		handler.Serve(r)
+	}
+}
+
+func sameTask(a, b timeserver.Task) bool {
+	return a.Index == b.Index && a.Loop == b.Loop && a.Name == b.Name
+}
+
+func shell(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+func env(r timeserver.Result) []string {
+	var errMsg string
+	if r.Error != nil {
+		errMsg = r.Error.Error()
+	}
+	return []string{
+		fmt.Sprintf("GOALARM_STATUS=%s", r.Status),
+		fmt.Sprintf("GOALARM_LEFT=%s", r.Left),
+		fmt.Sprintf("GOALARM_TASK=%s", r.Task.Name),
+		fmt.Sprintf("GOALARM_INDEX=%s", strconv.Itoa(r.Task.Index)),
+		fmt.Sprintf("GOALARM_ERROR=%s", errMsg),
+	}
+}
@@ -0,0 +1,84 @@
+package hook_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/komem3/goalarm/internal/hook"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+func TestCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook test uses sh")
+	}
+	out := filepath.Join(t.TempDir(), "hook.txt")
+	h := hook.New(`echo "$GOALARM_STATUS $GOALARM_TASK $GOALARM_INDEX $GOALARM_LEFT" > ` + out)
+	h.Serve(timeserver.Result{
+		Status: timeserver.FinishStatus,
+		Left:   "0s",
+		Task:   timeserver.Task{Index: 1, Name: "working"},
+	})
+
+	var given []byte
+	for deadline := time.Now().Add(time.Second * 5); time.Now().Before(deadline); time.Sleep(time.Millisecond * 10) {
+		b, err := ioutil.ReadFile(out)
+		if err == nil && len(b) > 0 && b[len(b)-1] == '\n' {
+			given = b
+			break
+		}
+	}
+	if diff := cmp.Diff(string(given), "finish working 1 0s\n"); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var given []string
+	record := timeserver.HandlerFunc(func(r timeserver.Result) {
+		given = append(given, fmt.Sprintf("%s %s %s", r.Status, r.Task.Name, r.Left))
+	})
+	h := hook.NewHooks(map[timeserver.Status]timeserver.Handler{
+		timeserver.RunningStatus: record,
+		timeserver.PauseStatus:   record,
+		timeserver.FinishStatus:  record,
+		timeserver.WarningStatus: record,
+	})
+	working := timeserver.Task{Index: 1, Range: time.Minute, Name: "working"}
+	breaking := timeserver.Task{Index: 2, Range: time.Minute * 5, Name: "break"}
+
+	h.Start(working)
+	for _, r := range []timeserver.Result{
+		{Status: timeserver.RunningStatus, Left: "59s", Task: working},
+		{Status: timeserver.RunningStatus, Left: "58s", Task: working},
+		{Status: timeserver.PauseStatus, Left: "50s", Task: working},
+		{Status: timeserver.PauseStatus, Left: "50s", Task: working},
+		{Status: timeserver.RunningStatus, Left: "50s", Task: working},
+		{Status: timeserver.WarningStatus, Left: "10s", Task: working},
+		{Status: timeserver.WarningStatus, Left: "5s", Task: working},
+		{Status: timeserver.RunningStatus, Left: "4s", Task: working},
+		{Status: timeserver.FinishStatus, Task: working},
+	} {
+		h.Serve(r)
+	}
+	h.Start(breaking)
+	h.Serve(timeserver.Result{Status: timeserver.RunningStatus, Left: "4m59s", Task: breaking})
+
+	want := []string{
+		"running working 1m0s",
+		"pause working 50s",
+		"running working 50s",
+		"warning working 10s",
+		"warning working 5s",
+		"finish working ",
+		"running break 5m0s",
+	}
+	if diff := cmp.Diff(given, want); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+}
@@ -0,0 +1,7 @@
+package log
+
+import "time"
+
+func (l *Logger) SetNow(t time.Time) {
+	l.sink.now = func() time.Time { return t }
+}
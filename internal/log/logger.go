@@ -1,18 +1,262 @@
 package log
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
-var verbose bool
+// Level is the severity of log.
+type Level int
 
-func SetVerbose(v bool) {
-	verbose = v
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+var levelNames = map[Level]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+var ErrUnknownLevel = errors.New("unknown log level")
+
+// ParseLevel returns level of s. e.g. "debug", "info", "warn", "error"
+func ParseLevel(s string) (Level, error) {
+	for l, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("'%s' is %w", s, ErrUnknownLevel)
+}
+
+// Format is the format of log line.
+type Format string
+
+const (
+	TextFormat Format = "text"
+	JSONFormat Format = "json"
+)
+
+var ErrUnknownFormat = errors.New("unknown log format")
+
+// Validate returns error if f is unknown format.
+func (f Format) Validate() error {
+	switch f {
+	case TextFormat, JSONFormat:
+		return nil
+	}
+	return fmt.Errorf("'%s' is %w", f, ErrUnknownFormat)
+}
+
+const timeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// sink is the writer shared by loggers derived by With.
+type sink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	level  Level
+	format Format
+	now    func() time.Time
+}
+
+type field struct {
+	key   string
+	value interface{}
+}
+
+// Logger writes leveled lines with fields.
+type Logger struct {
+	sink   *sink
+	fields []field
+}
+
+// New returns logger writing lines of level or higher to w.
+func New(w io.Writer, level Level, format Format) *Logger {
+	return &Logger{sink: &sink{
+		w:      w,
+		level:  level,
+		format: format,
+		now:    time.Now,
+	}}
+}
+
+// With returns logger adding fields of key-value pairs to every line.
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	fields := make([]field, len(l.fields), len(l.fields)+len(keyvals)/2+1)
+	copy(fields, l.fields)
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if i+1 == len(keyvals) {
+			fields = append(fields, field{"!BADKEY", key})
+			break
+		}
+		fields = append(fields, field{key, keyvals[i+1]})
+	}
+	return &Logger{sink: l.sink, fields: fields}
+}
+
+// Enabled reports whether lines of level are written.
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.sink.level
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(DebugLevel, format, args...)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(InfoLevel, format, args...)
+}
+
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(WarnLevel, format, args...)
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(ErrorLevel, format, args...)
+}
+
+func (l *Logger) logf(level Level, format string, args ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	msg := strings.TrimRight(fmt.Sprintf(format, args...), "\n")
+	fields := append([]field{
+		{"time", l.sink.now().Format(timeFormat)},
+		{"level", level.String()},
+		{"msg", msg},
+	}, l.fields...)
+
+	var buf bytes.Buffer
+	if l.sink.format == JSONFormat {
+		writeJSON(&buf, fields)
+	} else {
+		writeText(&buf, fields)
+	}
+	buf.WriteByte('\n')
+
+	l.sink.mu.Lock()
+	defer l.sink.mu.Unlock()
+	if _, err := l.sink.w.Write(buf.Bytes()); err != nil {
+		fmt.Fprintf(os.Stderr, "write log: %v\n", err)
+	}
+}
+
+// plain returns v as a value which is printed simply.
+func plain(v interface{}) interface{} {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return v
+}
+
+func writeText(buf *bytes.Buffer, fields []field) {
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		s := fmt.Sprint(plain(f.value))
+		if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+			s = strconv.Quote(s)
+		}
+		buf.WriteString(f.key)
+		buf.WriteByte('=')
+		buf.WriteString(s)
+	}
+}
+
+func writeJSON(buf *bytes.Buffer, fields []field) {
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.key)
+		value, err := json.Marshal(plain(f.value))
+		if err != nil {
+			value, _ = json.Marshal(fmt.Sprint(f.value))
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger = New(os.Stderr, ErrorLevel, TextFormat)
+)
+
+// SetDefault sets logger used by package functions.
+func SetDefault(l *Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger = l
+}
+
+// Default returns logger used by package functions.
+func Default() *Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+func With(keyvals ...interface{}) *Logger {
+	return Default().With(keyvals...)
+}
+
+func Debugf(format string, args ...interface{}) {
+	Default().logf(DebugLevel, format, args...)
+}
+
+func Infof(format string, args ...interface{}) {
+	Default().logf(InfoLevel, format, args...)
+}
+
+func Warnf(format string, args ...interface{}) {
+	Default().logf(WarnLevel, format, args...)
+}
+
+func Errorf(format string, args ...interface{}) {
+	Default().logf(ErrorLevel, format, args...)
+}
+
+type contextKey struct{}
+
+// NewContext returns ctx carrying l.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
 }
 
-func Printf(format string, args ...interface{}) {
-	if verbose {
-		fmt.Fprintf(os.Stderr, format, args...)
+// FromContext returns logger of ctx, or default logger if ctx has no logger.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
 	}
+	return Default()
 }
@@ -0,0 +1,88 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/komem3/goalarm/internal/log"
+)
+
+func TestLogger(t *testing.T) {
+	now := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		level  log.Level
+		format log.Format
+		want   string
+	}{
+		{
+			name:   "text",
+			level:  log.InfoLevel,
+			format: log.TextFormat,
+			want: `time=2021-01-01T10:00:00.000Z level=info msg="run task: 20m0s" task=working index=1
+time=2021-01-01T10:00:00.000Z level=warn msg=result task=working index=1 status=error error="bad \"get now\""
+`,
+		},
+		{
+			name:   "json",
+			level:  log.DebugLevel,
+			format: log.JSONFormat,
+			want: `{"time":"2021-01-01T10:00:00.000Z","level":"debug","msg":"start"}
+{"time":"2021-01-01T10:00:00.000Z","level":"info","msg":"run task: 20m0s","task":"working","index":1}
+{"time":"2021-01-01T10:00:00.000Z","level":"warn","msg":"result","task":"working","index":1,"status":"error","error":"bad \"get now\""}
+`,
+		},
+		{
+			name:   "error level",
+			level:  log.ErrorLevel,
+			format: log.TextFormat,
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			logger := log.New(&buf, tt.level, tt.format)
+			logger.SetNow(now)
+			logger.Debugf("start\n")
+			task := logger.With("task", "working", "index", 1)
+			task.Infof("run task: %s", time.Minute*20)
+			task.With("status", "error", "error", errors.New(`bad "get now"`)).Warnf("result")
+
+			if diff := cmp.Diff(buf.String(), tt.want); diff != "" {
+				t.Errorf("given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, level := range []log.Level{log.DebugLevel, log.InfoLevel, log.WarnLevel, log.ErrorLevel} {
+		given, err := log.ParseLevel(level.String())
+		if err != nil {
+			t.Error(err)
+		}
+		if given != level {
+			t.Errorf("given %s, want %s", given, level)
+		}
+	}
+	if _, err := log.ParseLevel("trace"); !errors.Is(err, log.ErrUnknownLevel) {
+		t.Errorf("given %v, want %v", err, log.ErrUnknownLevel)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if log.FromContext(context.Background()) != log.Default() {
+		t.Error("context without logger should return default logger")
+	}
+	logger := log.Default().With("task", "working")
+	if log.FromContext(log.NewContext(context.Background(), logger)) != logger {
+		t.Error("context should return its logger")
+	}
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotateFile is the log file rotated by size.
+// Old files are renamed to path.1, path.2, ... and files over backups are removed.
+type RotateFile struct {
+	path    string
+	maxSize int64
+	backups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenRotateFile opens path to append. maxSize 0 disables rotation.
+func OpenRotateFile(path string, maxSize int64, backups int) (*RotateFile, error) {
+	f := &RotateFile{
+		path:    path,
+		maxSize: maxSize,
+		backups: backups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotateFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Write writes p to the file. The file is rotated before it is over max size.
+func (f *RotateFile) Write(p []byte) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, fmt.Errorf("rotate %s: %w", f.path, err)
+		}
+	}
+	n, err = f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate moves the file to backups and opens new one.
+// When moving fails, the current file is reopened to keep writing.
+func (f *RotateFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	if err := f.shift(); err != nil {
+		if oerr := f.open(); oerr != nil {
+			return oerr
+		}
+		return err
+	}
+	return f.open()
+}
+
+// shift renames path to path.1, path.1 to path.2, ... and removes the file over backups.
+func (f *RotateFile) shift() error {
+	if f.backups <= 0 {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	for i := f.backups - 1; i > 0; i-- {
+		err := os.Rename(backupPath(f.path, i), backupPath(f.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(f.path, backupPath(f.path, 1))
+}
+
+func backupPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+func (f *RotateFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
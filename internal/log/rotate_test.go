@@ -0,0 +1,93 @@
+package log_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/komem3/goalarm/internal/log"
+)
+
+func TestRotateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "goalarm.log")
+
+	f, err := log.OpenRotateFile(path, 8, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	given := make(map[string]string)
+	files, err := filepath.Glob(path + "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		given[filepath.Base(file)] = string(b)
+	}
+	want := map[string]string{
+		"goalarm.log":   "fourth\n",
+		"goalarm.log.1": "third\n",
+		"goalarm.log.2": "second\n",
+	}
+	if diff := cmp.Diff(given, want); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestRotateFile_RenameError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "goalarm.log")
+	// non-empty directory of the backup makes rename fail.
+	if err := os.MkdirAll(filepath.Join(path+".1", "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := log.OpenRotateFile(path, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("first\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("second\n")); err == nil {
+		t.Fatal("rotate error is expected")
+	}
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("third\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	given := make(map[string]string)
+	for _, file := range []string{path, path + ".1"} {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		given[filepath.Base(file)] = string(b)
+	}
+	want := map[string]string{
+		"goalarm.log":   "third\n",
+		"goalarm.log.1": "first\n",
+	}
+	if diff := cmp.Diff(given, want); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+}
@@ -0,0 +1,7 @@
+package metrics
+
+import "time"
+
+func (m *Recorder) SetNow(t time.Time) {
+	m.now = func() time.Time { return t }
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+// Recorder counts task transitions from results, and serves them as OpenMetrics.
+type Recorder struct {
+	mu  sync.Mutex
+	now func() time.Time
+
+	started  map[string]int
+	finished map[string]int
+	stopped  map[string]int
+	pauses   map[string]int
+	errors   map[string]int
+	// focused is running seconds of ended tasks.
+	focused map[string]float64
+
+	current *current
+}
+
+// current is the state of running task.
+type current struct {
+	task     timeserver.Task
+	status   timeserver.Status
+	left     time.Duration
+	deadline time.Time
+}
+
+var _ timeserver.Handler = (*Recorder)(nil)
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		now:      time.Now,
+		started:  make(map[string]int),
+		finished: make(map[string]int),
+		stopped:  make(map[string]int),
+		pauses:   make(map[string]int),
+		errors:   make(map[string]int),
+		focused:  make(map[string]float64),
+	}
+}
+
+// TaskStarted records start of task.
+func (m *Recorder) TaskStarted(task timeserver.Task) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.started[task.Name]++
+	m.current = &current{
+		task:     task,
+		status:   timeserver.RunningStatus,
+		left:     task.Range,
+		deadline: m.now().Add(task.Range),
+	}
+}
+
+// Serve records transition of result.
+func (m *Recorder) Serve(r timeserver.Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name := r.Task.Name
+	switch r.Status {
+	case timeserver.ErrorStatus:
+		code := string(r.Code())
+		if code == "" {
+			code = "other"
+		}
+		m.errors[code]++
+		return
+	case timeserver.FinishStatus:
+		m.finished[name]++
+	case timeserver.StopStatus:
+		m.stopped[name]++
+	}
+
+	c := m.current
+	if c == nil {
+		return
+	}
+	switch r.Status {
+	case timeserver.FinishStatus, timeserver.StopStatus:
+		m.focused[name] += r.Elapsed().Seconds()
+		m.current = nil
+	case timeserver.PauseStatus:
+		if c.status != timeserver.PauseStatus {
+			m.pauses[name]++
+		}
+		c.status = r.Status
+		c.left = r.LeftTime
+	case timeserver.RunningStatus, timeserver.WarningStatus:
+		c.status = timeserver.RunningStatus
+		c.deadline = r.Deadline
+	}
+}
+
+// remaining returns left time of current task. mu must be locked.
+func (m *Recorder) remaining() time.Duration {
+	c := m.current
+	if c == nil {
+		return 0
+	}
+	if c.status == timeserver.PauseStatus {
+		return c.left
+	}
+	left := c.deadline.Sub(m.now())
+	if left < 0 {
+		return 0
+	}
+	return left
+}
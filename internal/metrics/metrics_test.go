@@ -0,0 +1,97 @@
+package metrics_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/komem3/goalarm/internal/metrics"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+func TestRecorder(t *testing.T) {
+	now := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	working := timeserver.Task{Index: 1, Range: time.Minute * 20, Name: "working"}
+	rest := timeserver.Task{Index: 2, Range: time.Minute * 5, Name: `break "long"`}
+
+	rec := metrics.NewRecorder()
+	rec.SetNow(now)
+	rec.TaskStarted(working)
+	rec.Serve(timeserver.Result{Status: timeserver.PauseStatus, LeftTime: time.Minute * 15, Task: working})
+	rec.Serve(timeserver.Result{Status: timeserver.PauseStatus, LeftTime: time.Minute * 15, Task: working})
+	rec.Serve(timeserver.Result{Status: timeserver.ErrorStatus, Error: timeserver.ErrUnknownCommand, Task: working})
+	rec.Serve(timeserver.Result{Status: timeserver.RunningStatus, LeftTime: time.Minute * 15, Deadline: now.Add(time.Minute * 15), Task: working})
+	rec.Serve(timeserver.Result{Status: timeserver.FinishStatus, Task: working})
+	rec.TaskStarted(rest)
+	rec.SetNow(now.Add(time.Minute * 2))
+
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(res.Header.Get("Content-Type"), "application/openmetrics-text; version=1.0.0; charset=utf-8"); diff != "" {
+		t.Errorf("content type: given(-), want(+)\n%s\n", diff)
+	}
+	want := fmt.Sprint(`# TYPE goalarm_tasks_started counter
+# HELP goalarm_tasks_started Number of started tasks.
+goalarm_tasks_started_total{name="break \"long\""} 1
+goalarm_tasks_started_total{name="working"} 1
+# TYPE goalarm_tasks_finished counter
+# HELP goalarm_tasks_finished Number of finished tasks.
+goalarm_tasks_finished_total{name="working"} 1
+# TYPE goalarm_tasks_stopped counter
+# HELP goalarm_tasks_stopped Number of stopped tasks.
+# TYPE goalarm_focused_seconds counter
+# HELP goalarm_focused_seconds Running seconds of tasks.
+goalarm_focused_seconds_total{name="break \"long\""} 120
+goalarm_focused_seconds_total{name="working"} 1200
+# TYPE goalarm_pauses counter
+# HELP goalarm_pauses Number of pauses.
+goalarm_pauses_total{name="working"} 1
+# TYPE goalarm_errors counter
+# HELP goalarm_errors Number of error results.
+goalarm_errors_total{code="unknown_command"} 1
+# TYPE goalarm_remaining_seconds gauge
+# HELP goalarm_remaining_seconds Left seconds of current task.
+goalarm_remaining_seconds 180
+# EOF
+`)
+	if diff := cmp.Diff(string(body), want); diff != "" {
+		t.Errorf("metrics: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestRecorder_Stop(t *testing.T) {
+	now := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	task := timeserver.Task{Range: time.Minute, Name: "alarm"}
+
+	rec := metrics.NewRecorder()
+	rec.SetNow(now)
+	rec.TaskStarted(task)
+	rec.Serve(timeserver.Result{Status: timeserver.StopStatus, LeftTime: time.Second * 20, Task: task})
+
+	rr := httptest.NewRecorder()
+	rec.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, line := range []string{
+		`goalarm_tasks_stopped_total{name="alarm"} 1`,
+		`goalarm_focused_seconds_total{name="alarm"} 40`,
+		`goalarm_remaining_seconds 0`,
+	} {
+		if !strings.Contains("\n"+rr.Body.String(), "\n"+line+"\n") {
+			t.Errorf("metrics has no line %q\n%s", line, rr.Body.String())
+		}
+	}
+}
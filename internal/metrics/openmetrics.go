@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const contentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// ServeHTTP writes metrics in OpenMetrics text format.
+func (m *Recorder) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	if err := m.WriteText(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WriteText writes metrics in OpenMetrics text format.
+func (m *Recorder) WriteText(w io.Writer) error {
+	m.mu.Lock()
+	focused := make(map[string]float64, len(m.focused)+1)
+	for name, sec := range m.focused {
+		focused[name] = sec
+	}
+	if c := m.current; c != nil {
+		focused[c.task.Name] += (c.task.Range - m.remaining()).Seconds()
+	}
+	var b strings.Builder
+	writeCounter(&b, "goalarm_tasks_started", "Number of started tasks.", "name", intValues(m.started))
+	writeCounter(&b, "goalarm_tasks_finished", "Number of finished tasks.", "name", intValues(m.finished))
+	writeCounter(&b, "goalarm_tasks_stopped", "Number of stopped tasks.", "name", intValues(m.stopped))
+	writeCounter(&b, "goalarm_focused_seconds", "Running seconds of tasks.", "name", focused)
+	writeCounter(&b, "goalarm_pauses", "Number of pauses.", "name", intValues(m.pauses))
+	writeCounter(&b, "goalarm_errors", "Number of error results.", "code", intValues(m.errors))
+	fmt.Fprintf(&b, "# TYPE goalarm_remaining_seconds gauge\n")
+	fmt.Fprintf(&b, "# HELP goalarm_remaining_seconds Left seconds of current task.\n")
+	fmt.Fprintf(&b, "goalarm_remaining_seconds %s\n", formatValue(m.remaining().Seconds()))
+	m.mu.Unlock()
+
+	b.WriteString("# EOF\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func intValues(m map[string]int) map[string]float64 {
+	values := make(map[string]float64, len(m))
+	for k, v := range m {
+		values[k] = float64(v)
+	}
+	return values
+}
+
+func writeCounter(b *strings.Builder, name, help, label string, values map[string]float64) {
+	fmt.Fprintf(b, "# TYPE %s counter\n", name)
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(b, "%s_total{%s=\"%s\"} %s\n", name, label, escapeLabel(k), formatValue(values[k]))
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatValue(v float64) string {
+	return fmt.Sprintf("%g", v)
+}
@@ -0,0 +1,89 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+var statusColors = map[timeserver.Status]struct{ hex, tmux string }{
+	timeserver.RunningStatus: {"#a3be8c", "green"},
+	timeserver.PauseStatus:   {"#ebcb8b", "yellow"},
+	timeserver.WarningStatus: {"#d08770", "colour208"},
+	timeserver.FinishStatus:  {"#bf616a", "red"},
+	timeserver.StopStatus:    {"#bf616a", "red"},
+	timeserver.ErrorStatus:   {"#bf616a", "red"},
+}
+
+// i3barEncoder writes i3bar protocol.
+// https://i3wm.org/docs/i3bar-protocol.html
+type i3barEncoder struct {
+	w       io.Writer
+	started bool
+}
+
+type i3barBlock struct {
+	Name     string `json:"name"`
+	FullText string `json:"full_text"`
+	Color    string `json:"color,omitempty"`
+	Urgent   bool   `json:"urgent"`
+}
+
+func (e *i3barEncoder) Encode(r timeserver.Result) error {
+	if !e.started {
+		if _, err := io.WriteString(e.w, "{\"version\":1}\n[\n"); err != nil {
+			return err
+		}
+		e.started = true
+	}
+	b, err := json.Marshal([]i3barBlock{{
+		Name:     "goalarm",
+		FullText: label(r),
+		Color:    statusColors[r.Status].hex,
+		Urgent:   r.Status != timeserver.RunningStatus && r.Status != timeserver.PauseStatus,
+	}})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.w, "%s,\n", b)
+	return err
+}
+
+// waybarEncoder writes json of waybar custom module with "return-type": "json".
+type waybarEncoder struct {
+	j *json.Encoder
+}
+
+type waybarLine struct {
+	Text       string `json:"text"`
+	Alt        string `json:"alt"`
+	Tooltip    string `json:"tooltip"`
+	Class      string `json:"class"`
+	Percentage int    `json:"percentage"`
+}
+
+func (e *waybarEncoder) Encode(r timeserver.Result) error {
+	tooltip := fmt.Sprintf("%s: %s", r.Task.Name, r.Status)
+	if r.Error != nil {
+		tooltip += ": " + r.Error.Error()
+	}
+	return e.j.Encode(waybarLine{
+		Text:       label(r),
+		Alt:        string(r.Status),
+		Tooltip:    tooltip,
+		Class:      string(r.Status),
+		Percentage: NewModel(r).Progress,
+	})
+}
+
+// tmuxEncoder writes line with tmux style for status-right.
+type tmuxEncoder struct {
+	w io.Writer
+}
+
+func (e *tmuxEncoder) Encode(r timeserver.Result) error {
+	_, err := fmt.Fprintf(e.w, "#[fg=%s]%s#[default]\n", statusColors[r.Status].tmux, label(r))
+	return err
+}
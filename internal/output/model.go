@@ -0,0 +1,61 @@
+package output
+
+import (
+	"math"
+	"time"
+
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+// Model is the data of template output.
+//
+//	{{.Status}}    status of task. running, pause, stop, finish, warning or error.
+//	{{.Left}}      left time of task.
+//	{{.Elapsed}}   elapsed time of task.
+//	{{.Progress}}  progress percentage of task. 0 to 100.
+//	{{.Deadline}}  time when task finishes. It is zero time when task is not running.
+//	{{.Step}}      index of task in routine. It is 0 in alarm mode.
+//	{{.Total}}     number of tasks in routine. It is 0 in alarm mode.
+//	{{.Loop}}      count of finished loops.
+//	{{.Drift}}     how late task started against the schedule of routine.
+//	{{.Name}}      name of task.
+//	{{.Range}}     duration of task.
+//	{{.Error}}     error message. It is empty when no error.
+//	{{.Task}}      the task. e.g. {{.Task.Name}}
+type Model struct {
+	Status   timeserver.Status
+	Left     time.Duration
+	Elapsed  time.Duration
+	Progress int
+	Deadline time.Time
+	Step     int
+	Total    int
+	Loop     int
+	Drift    time.Duration
+	Name     string
+	Range    time.Duration
+	Error    string
+	Task     timeserver.Task
+}
+
+// NewModel converts result to Model.
+func NewModel(r timeserver.Result) Model {
+	m := Model{
+		Status:   r.Status,
+		Left:     r.LeftTime.Round(time.Second),
+		Elapsed:  r.Elapsed().Round(time.Second),
+		Progress: int(math.Round(r.Progress() * 100)),
+		Deadline: r.Deadline,
+		Step:     r.Task.Index,
+		Total:    r.Task.Total,
+		Loop:     r.Task.Loop,
+		Drift:    r.Task.Drift.Round(time.Second),
+		Name:     r.Task.Name,
+		Range:    r.Task.Range,
+		Task:     r.Task,
+	}
+	if r.Error != nil {
+		m.Error = r.Error.Error()
+	}
+	return m
+}
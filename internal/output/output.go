@@ -0,0 +1,114 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+const (
+	JSONFormat   = "json"
+	TextFormat   = "text"
+	I3barFormat  = "i3bar"
+	WaybarFormat = "waybar"
+	TmuxFormat   = "tmux"
+)
+
+var ErrUnknownFormat = errors.New("unknown output format")
+
+// Encoder writes result to output.
+type Encoder interface {
+	Encode(r timeserver.Result) error
+}
+
+// HelloEncoder is the Encoder which also writes handshake message. Only json format implements it.
+type HelloEncoder interface {
+	Encoder
+	Hello(h timeserver.Hello) error
+}
+
+// NewEncoder returns encoder of format.
+func NewEncoder(w io.Writer, format string) (Encoder, error) {
+	switch format {
+	case "", JSONFormat:
+		return &jsonEncoder{json.NewEncoder(w)}, nil
+	case TextFormat:
+		return &textEncoder{w: w}, nil
+	case I3barFormat:
+		return &i3barEncoder{w: w}, nil
+	case WaybarFormat:
+		return &waybarEncoder{j: json.NewEncoder(w)}, nil
+	case TmuxFormat:
+		return &tmuxEncoder{w: w}, nil
+	}
+	return nil, fmt.Errorf("'%s' is %w. support %s, %s, %s, %s or %s", format, ErrUnknownFormat,
+		JSONFormat, TextFormat, I3barFormat, WaybarFormat, TmuxFormat)
+}
+
+type jsonEncoder struct {
+	j *json.Encoder
+}
+
+func (e *jsonEncoder) Encode(r timeserver.Result) error {
+	return e.j.Encode(r)
+}
+
+func (e *jsonEncoder) Hello(h timeserver.Hello) error {
+	return e.j.Encode(h)
+}
+
+type textEncoder struct {
+	w io.Writer
+}
+
+func (e *textEncoder) Encode(r timeserver.Result) error {
+	line := fmt.Sprintf("%s %s", r.Task.Name, r.Status)
+	if r.Left != "" {
+		line += " " + r.Left
+	}
+	if r.Error != nil {
+		line += ": " + r.Error.Error()
+	}
+	_, err := fmt.Fprintln(e.w, line)
+	return err
+}
+
+// NewTemplateEncoder returns encoder writing text/template of Model.
+func NewTemplateEncoder(w io.Writer, text string) (Encoder, error) {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	return &templateEncoder{w: w, tmpl: tmpl}, nil
+}
+
+type templateEncoder struct {
+	w    io.Writer
+	tmpl *template.Template
+}
+
+func (e *templateEncoder) Encode(r timeserver.Result) error {
+	var b strings.Builder
+	if err := e.tmpl.Execute(&b, NewModel(r)); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(e.w, strings.TrimSuffix(b.String(), "\n"))
+	return err
+}
+
+// label is short text of result for status bars. e.g. "working 4m58s"
+func label(r timeserver.Result) string {
+	switch r.Status {
+	case timeserver.RunningStatus, timeserver.WarningStatus:
+		return fmt.Sprintf("%s %s", r.Task.Name, r.Left)
+	case timeserver.PauseStatus:
+		return fmt.Sprintf("%s %s (%s)", r.Task.Name, r.Left, r.Status)
+	default:
+		return fmt.Sprintf("%s %s", r.Task.Name, r.Status)
+	}
+}
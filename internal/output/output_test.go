@@ -0,0 +1,254 @@
+package output_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/komem3/goalarm/internal/output"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+func TestEncoder(t *testing.T) {
+	task := timeserver.Task{Index: 1, Range: time.Minute, Name: "working"}
+	results := []timeserver.Result{
+		{Status: timeserver.RunningStatus, Left: "45s", LeftTime: time.Second * 45, Task: task},
+		{Status: timeserver.PauseStatus, Left: "30s", LeftTime: time.Second * 30, Task: task},
+		{Status: timeserver.FinishStatus, Task: task},
+	}
+	tests := []struct {
+		name    string
+		given   string
+		want    string
+		wantErr error
+	}{
+		{
+			"json",
+			output.JSONFormat,
+			`{"status":"running","left":"45s","error":"","code":"","task":{"index":1,"range":"1m0s","name":"working"},"left_ms":45000,"elapsed_ms":15000,"deadline":null,"progress":0.25,"routine_step":1,"routine_total":0,"loop_count":0,"drift_ms":0}
+{"status":"pause","left":"30s","error":"","code":"","task":{"index":1,"range":"1m0s","name":"working"},"left_ms":30000,"elapsed_ms":30000,"deadline":null,"progress":0.5,"routine_step":1,"routine_total":0,"loop_count":0,"drift_ms":0}
+{"status":"finish","left":"","error":"","code":"","task":{"index":1,"range":"1m0s","name":"working"},"left_ms":0,"elapsed_ms":60000,"deadline":null,"progress":1,"routine_step":1,"routine_total":0,"loop_count":0,"drift_ms":0}
+`,
+			nil,
+		},
+		{
+			"text",
+			output.TextFormat,
+			"working running 45s\nworking pause 30s\nworking finish\n",
+			nil,
+		},
+		{
+			"i3bar",
+			output.I3barFormat,
+			`{"version":1}
+[
+[{"name":"goalarm","full_text":"working 45s","color":"#a3be8c","urgent":false}],
+[{"name":"goalarm","full_text":"working 30s (pause)","color":"#ebcb8b","urgent":false}],
+[{"name":"goalarm","full_text":"working finish","color":"#bf616a","urgent":true}],
+`,
+			nil,
+		},
+		{
+			"waybar",
+			output.WaybarFormat,
+			`{"text":"working 45s","alt":"running","tooltip":"working: running","class":"running","percentage":25}
+{"text":"working 30s (pause)","alt":"pause","tooltip":"working: pause","class":"pause","percentage":50}
+{"text":"working finish","alt":"finish","tooltip":"working: finish","class":"finish","percentage":100}
+`,
+			nil,
+		},
+		{
+			"tmux",
+			output.TmuxFormat,
+			"#[fg=green]working 45s#[default]\n#[fg=yellow]working 30s (pause)#[default]\n#[fg=red]working finish#[default]\n",
+			nil,
+		},
+		{"unknown", "xml", "", output.ErrUnknownFormat},
+		{"template", "{{.Name}}", "", output.ErrUnknownFormat},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			out := new(bytes.Buffer)
+			enc, err := output.NewEncoder(out, tt.given)
+			if diff := cmp.Diff(err, tt.wantErr, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("output.NewEncoder error: given(-), want(+)\n%s\n", diff)
+			}
+			if err != nil {
+				return
+			}
+			for _, r := range results {
+				if err := enc.Encode(r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := cmp.Diff(out.String(), tt.want); diff != "" {
+				t.Errorf("encode: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestTemplateEncoder(t *testing.T) {
+	out := new(bytes.Buffer)
+	enc, err := output.NewTemplateEncoder(out, "{{.Task.Name}}={{.Left}} {{.Progress}}%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := timeserver.Task{Range: time.Minute, Name: "working"}
+	for _, r := range []timeserver.Result{
+		{Status: timeserver.RunningStatus, Left: "45s", LeftTime: time.Second * 45, Task: task},
+		{Status: timeserver.FinishStatus, Task: task},
+	} {
+		if err := enc.Encode(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if diff := cmp.Diff(out.String(), "working=45s 25%\nworking=0s 100%\n"); diff != "" {
+		t.Errorf("encode: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestEncoder_Error(t *testing.T) {
+	out := new(bytes.Buffer)
+	enc, err := output.NewEncoder(out, output.TextFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = enc.Encode(timeserver.Result{
+		Status: timeserver.ErrorStatus,
+		Error:  errors.New("unknown command"),
+		Task:   timeserver.Task{Name: "alarm"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(out.String(), "alarm error: unknown command\n"); diff != "" {
+		t.Errorf("encode: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestNewModel(t *testing.T) {
+	task := timeserver.Task{Index: 2, Range: time.Minute * 4, Name: "break", Total: 3, Drift: time.Minute*2 + time.Millisecond*400}
+	tests := []struct {
+		name  string
+		given timeserver.Result
+		want  output.Model
+	}{
+		{
+			"running",
+			timeserver.Result{
+				Status:   timeserver.RunningStatus,
+				Left:     "3m0s",
+				LeftTime: time.Minute*3 - time.Millisecond*200,
+				Deadline: time.Date(2021, 1, 1, 10, 3, 0, 0, time.UTC),
+				Task:     task,
+			},
+			output.Model{
+				Status:   timeserver.RunningStatus,
+				Left:     time.Minute * 3,
+				Elapsed:  time.Minute,
+				Progress: 25,
+				Deadline: time.Date(2021, 1, 1, 10, 3, 0, 0, time.UTC),
+				Step:     2,
+				Total:    3,
+				Drift:    time.Minute * 2,
+				Name:     "break",
+				Range:    time.Minute * 4,
+				Task:     task,
+			},
+		},
+		{
+			"pause",
+			timeserver.Result{Status: timeserver.PauseStatus, Left: "1m0s", LeftTime: time.Minute, Task: task},
+			output.Model{
+				Status:   timeserver.PauseStatus,
+				Left:     time.Minute,
+				Elapsed:  time.Minute * 3,
+				Progress: 75,
+				Step:     2,
+				Total:    3,
+				Drift:    time.Minute * 2,
+				Name:     "break",
+				Range:    time.Minute * 4,
+				Task:     task,
+			},
+		},
+		{
+			"finish",
+			timeserver.Result{Status: timeserver.FinishStatus, Task: task},
+			output.Model{
+				Status:   timeserver.FinishStatus,
+				Elapsed:  time.Minute * 4,
+				Progress: 100,
+				Step:     2,
+				Total:    3,
+				Drift:    time.Minute * 2,
+				Name:     "break",
+				Range:    time.Minute * 4,
+				Task:     task,
+			},
+		},
+		{
+			"error",
+			timeserver.Result{Status: timeserver.ErrorStatus, Error: errors.New("bad"), Task: timeserver.Task{Name: "alarm"}},
+			output.Model{
+				Status: timeserver.ErrorStatus,
+				Name:   "alarm",
+				Error:  "bad",
+				Task:   timeserver.Task{Name: "alarm"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(output.NewModel(tt.given), tt.want); diff != "" {
+				t.Errorf("output.NewModel: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestEncoder_Hello(t *testing.T) {
+	tests := []struct {
+		name  string
+		given string
+		want  string
+	}{
+		{"json", output.JSONFormat, `{"type":"hello","protocol":1,"version":"v1","commands":["get"],"statuses":["running"]}` + "\n"},
+		{"text", output.TextFormat, ""},
+		{"i3bar", output.I3barFormat, ""},
+		{"tmux", output.TmuxFormat, ""},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			out := new(bytes.Buffer)
+			enc, err := output.NewEncoder(out, tt.given)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if enc, ok := enc.(output.HelloEncoder); ok {
+				err = enc.Hello(timeserver.Hello{
+					Type:     timeserver.HelloType,
+					Protocol: 1,
+					Version:  "v1",
+					Commands: []timeserver.Command{timeserver.GetCommand},
+					Statuses: []timeserver.Status{timeserver.RunningStatus},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := cmp.Diff(out.String(), tt.want); diff != "" {
+				t.Errorf("hello: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package routine
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrBadClock = errors.New("is bad clock format. e.g. 10:30 or 10:30:15")
+
+// Clock is the time of day since midnight.
+type Clock time.Duration
+
+var clockLayouts = []string{"15:04:05", "15:04"}
+
+// ParseClock parses "10:30" or "10:30:15".
+func ParseClock(s string) (Clock, error) {
+	for _, layout := range clockLayouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		return Clock(time.Duration(t.Hour())*time.Hour +
+			time.Duration(t.Minute())*time.Minute +
+			time.Duration(t.Second())*time.Second), nil
+	}
+	return 0, fmt.Errorf("'%s' %w", s, ErrBadClock)
+}
+
+func (c Clock) String() string {
+	h, m, s := c.hms()
+	if s == 0 {
+		return fmt.Sprintf("%02d:%02d", h, m)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+}
+
+// Next returns the first time of c at or after t in the location of t.
+func (c Clock) Next(t time.Time) time.Time {
+	if c.Passed(t) {
+		return c.on(t, 1)
+	}
+	return c.on(t, 0)
+}
+
+// Passed reports whether c of the day of t is before t, so that Next is on the next day.
+func (c Clock) Passed(t time.Time) bool {
+	return c.on(t, 0).Before(t)
+}
+
+// on returns c of days after the day of t. It is wall clock time even on the day of DST change.
+func (c Clock) on(t time.Time, days int) time.Time {
+	h, m, s := c.hms()
+	return time.Date(t.Year(), t.Month(), t.Day()+days, h, m, s, 0, t.Location())
+}
+
+func (c Clock) hms() (h, m, s int) {
+	d := time.Duration(c)
+	return int(d / time.Hour), int(d % time.Hour / time.Minute), int(d % time.Minute / time.Second)
+}
@@ -0,0 +1,109 @@
+package routine_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/komem3/goalarm/internal/routine"
+)
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		given   string
+		want    string
+		wantErr error
+	}{
+		{"10:30", "10:30", nil},
+		{"9:05:15", "09:05:15", nil},
+		{"00:00", "00:00", nil},
+		{"24:00", "", routine.ErrBadClock},
+		{"10m", "", routine.ErrBadClock},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.given, func(t *testing.T) {
+			t.Parallel()
+			c, err := routine.ParseClock(tt.given)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("given %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(c.String(), tt.want); diff != "" {
+				t.Errorf("given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestClock_Next(t *testing.T) {
+	clock, err := routine.ParseClock("10:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		given time.Time
+		want  time.Time
+	}{
+		{
+			"before clock",
+			time.Date(2021, 1, 1, 9, 0, 0, 0, time.UTC),
+			time.Date(2021, 1, 1, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			"at clock",
+			time.Date(2021, 1, 1, 10, 30, 0, 0, time.UTC),
+			time.Date(2021, 1, 1, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			"after clock",
+			time.Date(2021, 12, 31, 10, 31, 0, 0, time.UTC),
+			time.Date(2022, 1, 1, 10, 30, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(clock.Next(tt.given), tt.want); diff != "" {
+				t.Errorf("given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestClock_NextDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	clock, err := routine.ParseClock("10:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// clocks go forward at 2:00 on 2021-03-14.
+	given := clock.Next(time.Date(2021, 3, 14, 1, 0, 0, 0, loc))
+	if diff := cmp.Diff(given.Format("2006-01-02 15:04 MST"), "2021-03-14 10:30 EDT"); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestClock_Passed(t *testing.T) {
+	clock, err := routine.ParseClock("10:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for given, want := range map[time.Time]bool{
+		time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC):  false,
+		time.Date(2021, 1, 1, 10, 30, 0, 0, time.UTC): false,
+		time.Date(2021, 1, 1, 11, 0, 0, 0, time.UTC):  true,
+	} {
+		if diff := cmp.Diff(clock.Passed(given), want); diff != "" {
+			t.Errorf("%s: given(-), want(+)\n%s\n", given, diff)
+		}
+	}
+}
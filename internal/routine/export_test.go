@@ -1,7 +1,34 @@
 package routine
 
-import "github.com/komem3/goalarm/internal/testutil"
+import (
+	"context"
+	"time"
+
+	"github.com/komem3/goalarm/internal/sound"
+	"github.com/komem3/goalarm/internal/testutil"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
 
 func SetMock() {
 	newAlarm = testutil.NewMockAlarm
+	newAnnouncement = testutil.NewMockAnnouncement
+}
+
+func Schedule(step Step, cursor, start time.Time) (timeserver.Task, time.Time) {
+	return step.schedule(cursor, start)
+}
+
+func AnnounceStep(step Step) string {
+	return announceStep(step)
+}
+
+// SetNewAlarm replaces players of alarm and returns the function restoring them.
+func SetNewAlarm(f func(sound.Config) (sound.Player, error)) func() {
+	old := newAlarm
+	newAlarm = f
+	return func() { newAlarm = old }
+}
+
+func LogDrift(ctx context.Context, step Step, task timeserver.Task) {
+	logDrift(ctx, step, task)
 }
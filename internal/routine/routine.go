@@ -1,95 +1,449 @@
 package routine
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
-	"os"
-	"sort"
+	"strings"
 	"time"
 
 	"github.com/komem3/goalarm/internal/log"
+	"github.com/komem3/goalarm/internal/output"
 	"github.com/komem3/goalarm/internal/sound"
 	"github.com/komem3/goalarm/internal/timeserver"
 )
 
-type Routine []timeserver.Task
+type Routine []Step
 
-var newAlarm = sound.NewAalarm
+// Step is a task of routine.
+// Sound overrides the sound of routine in this step.
+// Warning is the sound of task warnings. No sound is played when it has no file and tone.
+// Until ends the step at the clock instead of Range. Range is computed when the step starts.
+type Step struct {
+	timeserver.Task
+	Sound   sound.Config
+	Warning sound.Config
+	Until   *Clock
+}
+
+// end returns the scheduled end of the step started at start.
+func (s Step) end(start time.Time) time.Time {
+	if s.Until != nil {
+		return s.Until.Next(start)
+	}
+	return start.Add(s.Range)
+}
+
+// schedule returns task of step started at start and the scheduled end.
+// cursor is the scheduled start. Range of until step is the rest to the clock, or zero when it is over.
+func (s Step) schedule(cursor, start time.Time) (timeserver.Task, time.Time) {
+	task := s.Task
+	end := s.end(cursor)
+	if s.Until != nil {
+		task.Range = end.Sub(start)
+		if task.Range < 0 {
+			task.Range = 0
+		}
+	}
+	task.Drift = start.Sub(cursor)
+	return task, end
+}
+
+// Config is the setting of running routine and alarm.
+// Format is output format of results. See output.NewEncoder.
+// Template is text/template of output.Model. It overrides Format.
+// Tick is interval of writing current status. Zero writes only on command.
+// Strict stops routine by command error.
+// OnEOF is the behavior when r is closed.
+// Commands are executed by the running task in addition to commands from r. e.g. signals
+// Reload replaces routine from the next step.
+// Subscribers observe results of all steps in addition to output.
+// OnTask is called when each task starts.
+type Config struct {
+	Sound       sound.Config
+	Loop        bool
+	Format      string
+	Template    string
+	Tick        time.Duration
+	Strict      bool
+	OnEOF       timeserver.EOFPolicy
+	Commands    <-chan timeserver.Command
+	Reload      <-chan Routine
+	Subscribers []Subscriber
+	OnTask      func(task timeserver.Task)
+}
 
-func RunRoutine(r io.Reader, w io.Writer, routine Routine, file string, loop bool) error {
-	alarm, err := newAlarm(file)
+// Subscriber is the handler of results with statuses. No statuses handles all results.
+type Subscriber struct {
+	Handler  timeserver.Handler
+	Statuses []timeserver.Status
+}
+
+// encoder validates config and returns encoder of output.
+func (c Config) encoder(w io.Writer) (output.Encoder, error) {
+	if err := c.OnEOF.Validate(); err != nil {
+		return nil, err
+	}
+	if c.Template != "" {
+		return output.NewTemplateEncoder(w, c.Template)
+	}
+	return output.NewEncoder(w, c.Format)
+}
+
+var (
+	newAlarm        = sound.New
+	newAnnouncement = sound.NewAnnouncement
+)
+
+func RunRoutine(r io.Reader, w io.Writer, routine Routine, c Config) error {
+	return RunRoutineContext(context.Background(), r, w, routine, c)
+}
+
+// RunRoutineContext is RunRoutine which stops timer and sound when ctx is done.
+// It returns the error of ctx then.
+// The goroutine reading r exits when r returns the next line or error.
+// Drift of each task is how late it started against the schedule from the start of routine.
+func RunRoutineContext(ctx context.Context, r io.Reader, w io.Writer, routine Routine, c Config) error {
+	enc, err := c.encoder(w)
 	if err != nil {
 		return err
 	}
-	jw := json.NewEncoder(w)
-	sort.Slice(routine, func(i, j int) bool {
-		return routine[i].Index < routine[j].Index
-	})
-	for l := true; l; l = loop {
-		for i, task := range routine {
+	if err := hello(enc); err != nil {
+		return err
+	}
+	p, err := newPlan(routine, c.Sound)
+	if err != nil {
+		return err
+	}
+	in := timeserver.NewInput(r)
+	defer in.Close()
+	cursor := time.Now()
+	for loop := 0; loop == 0 || c.Loop; loop++ {
+		for i := 0; i < len(p.routine); i++ {
+			select {
+			case next := <-c.Reload:
+				p = p.reload(next, c.Sound)
+			default:
+			}
+			if i >= len(p.routine) {
+				break
+			}
+			step := p.routine[i]
+			nextDay := loop == 0 && step.Until != nil && step.Until.Passed(cursor)
+			var task timeserver.Task
+			task, cursor = step.schedule(cursor, time.Now())
 			task.Index = i + 1
-			result, err := runTask(r, jw, task)
+			task.Total = len(p.routine)
+			task.Loop = loop
+			tctx := taskContext(ctx, task)
+			logDrift(tctx, step, task)
+			if nextDay {
+				log.FromContext(tctx).Warnf("until %s has passed at the routine start, so the step ends on the next day", step.Until)
+			}
+			result, err := runTask(tctx, in, enc, task, p.warnings[i], c)
 			if err != nil {
 				return err
 			}
 			if result.Status == timeserver.StopStatus {
 				return nil
 			}
-			if len(routine)-1 == i && !loop {
-				alarm.PlayWait()
-			} else {
-				alarm.Play()
+			if len(p.routine)-1 == i && !c.Loop {
+				p.alarms[i].PlayContext(tctx)
+				continue
+			}
+			alarm := p.alarms[i]
+			if c.Sound.Announce != "" {
+				next := p.routine[(i+1)%len(p.routine)]
+				alarm = newAnnouncement(c.Sound, announceStep(next), alarm)
 			}
+			go alarm.PlayContext(tctx)
 		}
 	}
-	return nil
+	return ctx.Err()
 }
 
-func RunAlarm(r io.Reader, w io.Writer, d time.Duration, file string, loop bool) error {
-	alarm, err := newAlarm(file)
+// plan is the routine sorted by index with players of each step.
+type plan struct {
+	routine  Routine
+	alarms   []sound.Player
+	warnings []sound.Player
+}
+
+func newPlan(routine Routine, snd sound.Config) (plan, error) {
+	if err := routine.Validate(); err != nil {
+		return plan{}, err
+	}
+	routine = routine.sorted()
+	alarms, warnings, err := stepAlarms(routine, snd)
 	if err != nil {
+		return plan{}, err
+	}
+	return plan{routine, alarms, warnings}, nil
+}
+
+// reload returns plan of next routine. The current plan is kept when next is bad.
+func (p plan) reload(next Routine, snd sound.Config) plan {
+	np, err := newPlan(next, snd)
+	if err != nil {
+		log.Warnf("reload routine: %v", err)
+		return p
+	}
+	log.Infof("reload routine: %d steps", len(next))
+	return np
+}
+
+// playerKey is the comparable form of sound.Config.
+type playerKey struct {
+	files    string
+	order    sound.Order
+	tone     string
+	silent   bool
+	wavOut   string
+	announce string
+	effect   sound.Effect
+}
+
+func newPlayerKey(c sound.Config) playerKey {
+	return playerKey{
+		files:    strings.Join(c.Files, "\x00"),
+		order:    c.Order,
+		tone:     c.Tone,
+		silent:   c.Silent,
+		wavOut:   c.WavOut,
+		announce: c.Announce,
+		effect:   c.Effect,
+	}
+}
+
+// stepAlarms prepares players of each step.
+// Steps with same sound share the player.
+func stepAlarms(routine Routine, snd sound.Config) (alarms, warnings []sound.Player, err error) {
+	cache := make(map[playerKey]sound.Player)
+	player := func(c sound.Config) (sound.Player, error) {
+		key := newPlayerKey(c)
+		if alarm, ok := cache[key]; ok {
+			return alarm, nil
+		}
+		alarm, err := newAlarm(c)
+		if err != nil {
+			return nil, err
+		}
+		cache[key] = alarm
+		return alarm, nil
+	}
+
+	alarms = make([]sound.Player, len(routine))
+	warnings = make([]sound.Player, len(routine))
+	for i, step := range routine {
+		if alarms[i], err = player(snd.Override(step.Sound)); err != nil {
+			return nil, nil, err
+		}
+		if len(step.Warning.Files) == 0 && step.Warning.Tone == "" {
+			warnings[i] = sound.Null{}
+			continue
+		}
+		if warnings[i], err = player(snd.Override(step.Warning)); err != nil {
+			return nil, nil, err
+		}
+	}
+	return alarms, warnings, nil
+}
+
+// logDrift logs that the task starts late.
+// It is warning only for until step, because the late start shortens the step.
+func logDrift(ctx context.Context, step Step, task timeserver.Task) {
+	if task.Drift < time.Second {
+		return
+	}
+	logger := log.FromContext(ctx)
+	if step.Until != nil {
+		logger.Warnf("step starts %s late, %s left until %s", task.Drift.Round(time.Second), task.Range.Round(time.Second), step.Until)
+		return
+	}
+	logger.Infof("step starts %s late", task.Drift.Round(time.Second))
+}
+
+// announceText is the text telling next task. e.g. "break, 5 minutes"
+func announceText(task timeserver.Task) string {
+	return fmt.Sprintf("%s, %s", task.Name, speakDuration(task.Range))
+}
+
+// announceStep is announceText of step. e.g. "keynote, until 10:30"
+func announceStep(step Step) string {
+	if step.Until != nil {
+		return fmt.Sprintf("%s, until %s", step.Name, step.Until)
+	}
+	return announceText(step.Task)
+}
+
+func speakDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	var words []string
+	for _, u := range []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "hour"},
+		{time.Minute, "minute"},
+		{time.Second, "second"},
+	} {
+		n := int(d / u.unit)
+		d -= time.Duration(n) * u.unit
+		switch {
+		case n == 1:
+			words = append(words, fmt.Sprintf("1 %s", u.name))
+		case n > 1:
+			words = append(words, fmt.Sprintf("%d %ss", n, u.name))
+		}
+	}
+	if len(words) == 0 {
+		return "0 seconds"
+	}
+	return strings.Join(words, " ")
+}
+
+// RunAlarm runs a task of step.
+// Index of the task is always 0 and empty name is "alarm".
+func RunAlarm(r io.Reader, w io.Writer, step Step, c Config) error {
+	return RunAlarmContext(context.Background(), r, w, step, c)
+}
+
+// RunAlarmContext is RunAlarm which stops timer and sound when ctx is done.
+// It returns the error of ctx then.
+func RunAlarmContext(ctx context.Context, r io.Reader, w io.Writer, step Step, c Config) error {
+	enc, err := c.encoder(w)
+	if err != nil {
+		return err
+	}
+	if err := hello(enc); err != nil {
+		return err
+	}
+	alarms, warnings, err := stepAlarms(Routine{step}, c.Sound)
+	if err != nil {
+		return err
+	}
+	alarm := alarms[0]
+	in := timeserver.NewInput(r)
+	defer in.Close()
+	task := step.Task
+	task.Index = 0
+	if task.Name == "" {
+		task.Name = "alarm"
+	}
+	if err := (Routine{{Task: task}}).Validate(); err != nil {
 		return err
 	}
-	jw := json.NewEncoder(w)
-	for l := true; l; l = loop {
-		result, err := runTask(r, jw,
-			timeserver.Task{
-				Index: 0,
-				Range: d,
-				Name:  "alarm",
-			})
+	for loop := 0; loop == 0 || c.Loop; loop++ {
+		task.Loop = loop
+		tctx := taskContext(ctx, task)
+		result, err := runTask(tctx, in, enc, task, warnings[0], c)
 		if err != nil {
 			return err
 		}
 		if result.Status == timeserver.StopStatus {
 			return nil
 		}
-		if loop {
-			alarm.Play()
+		if c.Loop {
+			go alarm.PlayContext(tctx)
 		} else {
-			alarm.PlayWait()
+			alarm.PlayContext(tctx)
 		}
 	}
+	return ctx.Err()
+}
+
+// hello writes handshake message. Output formats without it write nothing.
+func hello(enc output.Encoder) error {
+	if h, ok := enc.(output.HelloEncoder); ok {
+		return h.Hello(timeserver.NewHello(timeserver.HelloType))
+	}
 	return nil
 }
 
+// outputHandler writes results with the encoder.
+type outputHandler struct {
+	enc    output.Encoder
+	logger *log.Logger
+}
+
+// newOutputHandler returns the handler writing to enc.
+// It handles hello messages when enc writes them, so that capabilities command is available.
+func newOutputHandler(enc output.Encoder, logger *log.Logger) timeserver.Handler {
+	h := &outputHandler{enc: enc, logger: logger}
+	if he, ok := enc.(output.HelloEncoder); ok {
+		return &helloOutputHandler{outputHandler: h, enc: he}
+	}
+	return h
+}
+
+func (h *outputHandler) Serve(r timeserver.Result) {
+	if err := h.enc.Encode(r); err != nil {
+		h.logger.Errorf("write result: %v", err)
+	}
+}
+
+type helloOutputHandler struct {
+	*outputHandler
+	enc output.HelloEncoder
+}
+
+func (h *helloOutputHandler) ServeHello(m timeserver.Hello) {
+	if err := h.enc.Hello(m); err != nil {
+		h.logger.Errorf("write hello: %v", err)
+	}
+}
+
+// taskContext returns ctx with the logger adding fields of task.
+func taskContext(ctx context.Context, task timeserver.Task) context.Context {
+	logger := log.FromContext(ctx).With("task", task.Name, "index", task.Index, "loop", task.Loop)
+	return log.NewContext(ctx, logger)
+}
+
 func runTask(
-	r io.Reader,
-	jw *json.Encoder,
+	ctx context.Context,
+	in *timeserver.Input,
+	enc output.Encoder,
 	task timeserver.Task,
+	warning sound.Player,
+	c Config,
 ) (result timeserver.Result, err error) {
-	log.Printf("run task %s: %s\n", task.Name, task.Range)
+	logger := log.FromContext(ctx)
+	logger.Infof("run task: %s", task.Range)
 	tserver := timeserver.NewTimeServer(task)
+	tserver.SetTick(c.Tick)
+	tserver.SetStrict(c.Strict)
+	tserver.SetEOFPolicy(c.OnEOF)
+	tserver.SetCommands(c.Commands)
 	tserver.StartTimer()
-	tserver.HandlerFunc(func(r timeserver.Result) {
-		err := jw.Encode(r)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%v", err)
+	tserver.Subscribe(timeserver.HandlerFunc(func(timeserver.Result) {
+		warning.Play()
+	}), timeserver.WarningStatus)
+	tserver.Subscribe(timeserver.HandlerFunc(func(r timeserver.Result) {
+		l := logger.With("status", r.Status, "left", r.Left)
+		if r.Status == timeserver.ErrorStatus {
+			l.Infof("result: %v", r.Error)
+			return
+		}
+		if r.Error != nil {
+			l = l.With("error", r.Error)
 		}
-	})
+		l.Debugf("result")
+	}))
+	for _, s := range c.Subscribers {
+		tserver.Subscribe(s.Handler, s.Statuses...)
+	}
+	tserver.Subscribe(newOutputHandler(enc, logger))
 
-	result = tserver.Listen(r)
+	if c.OnTask != nil {
+		c.OnTask(task)
+	}
+	result = tserver.ListenContext(ctx, in)
+	if n := tserver.Dropped(); n > 0 {
+		logger.Warnf("drop %d results for slow subscribers", n)
+	}
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
 	if result.Error != nil {
 		return result, fmt.Errorf("server error : %w", result.Error)
 	}
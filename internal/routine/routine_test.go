@@ -1,21 +1,31 @@
 package routine_test
 
 import (
+	"bytes"
+	"context"
+	"io"
 	"io/ioutil"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/komem3/goalarm/internal/log"
+	"github.com/komem3/goalarm/internal/output"
 	"github.com/komem3/goalarm/internal/routine"
+	"github.com/komem3/goalarm/internal/sound"
 	"github.com/komem3/goalarm/internal/testutil"
 	"github.com/komem3/goalarm/internal/timeserver"
 )
 
 func TestRunRoutine(t *testing.T) {
 	type given struct {
-		r   routine.Routine
-		cmd string
+		r      routine.Routine
+		cmd    string
+		strict bool
+		onEOF  timeserver.EOFPolicy
 	}
 	tests := []struct {
 		name    string
@@ -26,16 +36,16 @@ func TestRunRoutine(t *testing.T) {
 			"stop",
 			given{
 				r: routine.Routine{
-					{
+					{Task: timeserver.Task{
 						Index: 2,
 						Range: time.Second * 10,
 						Name:  "second",
-					},
-					{
+					}},
+					{Task: timeserver.Task{
 						Index: 1,
-						Range: 0,
+						Range: time.Millisecond,
 						Name:  "first",
-					},
+					}},
 				},
 				cmd: "stop\n",
 			},
@@ -45,18 +55,19 @@ func TestRunRoutine(t *testing.T) {
 			"finish",
 			given{
 				r: routine.Routine{
-					{
+					{Task: timeserver.Task{
 						Index: 2,
-						Range: 0,
+						Range: time.Millisecond,
 						Name:  "second",
-					},
-					{
+					}},
+					{Task: timeserver.Task{
 						Index: 1,
-						Range: 0,
+						Range: time.Millisecond,
 						Name:  "first",
-					},
+					}},
 				},
-				cmd: "get\n",
+				cmd:   "get\n",
+				onEOF: timeserver.ContinueOnEOF,
 			},
 			nil,
 		},
@@ -64,21 +75,69 @@ func TestRunRoutine(t *testing.T) {
 			"bad command error",
 			given{
 				r: routine.Routine{
-					{
+					{Task: timeserver.Task{
 						Index: 1,
 						Range: time.Second * 100,
 						Name:  "first",
-					},
-					{
+					}},
+					{Task: timeserver.Task{
 						Index: 2,
 						Range: time.Second * 10,
 						Name:  "second",
-					},
+					}},
 				},
-				cmd: "unknown\n",
+				cmd:    "unknown\n",
+				strict: true,
 			},
 			timeserver.ErrUnknownCommand,
 		},
+		{
+			"zero range",
+			given{
+				r: routine.Routine{
+					{Task: timeserver.Task{
+						Index: 1,
+						Range: 0,
+						Name:  "first",
+					}},
+				},
+				cmd: "stop\n",
+			},
+			routine.ErrNonPositiveRange,
+		},
+		{
+			"duplicate index",
+			given{
+				r: routine.Routine{
+					{Task: timeserver.Task{
+						Index: 1,
+						Range: time.Second * 10,
+						Name:  "first",
+					}},
+					{Task: timeserver.Task{
+						Index: 1,
+						Range: time.Second * 10,
+						Name:  "second",
+					}},
+				},
+				cmd: "stop\n",
+			},
+			routine.ErrDuplicateIndex,
+		},
+		{
+			"bad command continue",
+			given{
+				r: routine.Routine{
+					{Task: timeserver.Task{
+						Index: 1,
+						Range: time.Second * 100,
+						Name:  "first",
+					}},
+				},
+				cmd: "unknown\nstop\n",
+			},
+			nil,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -88,8 +147,7 @@ func TestRunRoutine(t *testing.T) {
 				testutil.MockIn(tt.given.cmd),
 				ioutil.Discard,
 				tt.given.r,
-				"dummy",
-				false,
+				routine.Config{Sound: sound.Config{Files: []string{"dummy"}}, Strict: tt.given.strict, OnEOF: tt.given.onEOF},
 			)
 			if diff := cmp.Diff(err, tt.wantErr, cmpopts.EquateErrors()); diff != "" {
 				t.Errorf("routine.RunRoutine error: given(-), want(+)\n%s\n", diff)
@@ -102,24 +160,428 @@ func TestRunAlarm(t *testing.T) {
 	type given struct {
 		command  string
 		duration time.Duration
+		strict   bool
+		onEOF    timeserver.EOFPolicy
+	}
+	tests := []struct {
+		name    string
+		given   given
+		wantErr error
+	}{
+		{"stop", given{"stop\n", time.Second, false, ""}, nil},
+		{"finish", given{"get\n", time.Millisecond, false, timeserver.ContinueOnEOF}, nil},
+		{"zero range", given{"get\n", 0, false, ""}, routine.ErrNonPositiveRange},
+		{"bad command error", given{"unknown\n", time.Second, true, ""}, timeserver.ErrUnknownCommand},
+		{"bad command continue", given{"unknown\nstop\n", time.Second, false, ""}, nil},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := routine.RunAlarm(
+				testutil.MockIn(tt.given.command),
+				ioutil.Discard,
+				routine.Step{Task: timeserver.Task{Range: tt.given.duration}},
+				routine.Config{Sound: sound.Config{Files: []string{"dummy"}}, Strict: tt.given.strict, OnEOF: tt.given.onEOF},
+			)
+			if diff := cmp.Diff(err, tt.wantErr, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("routine.RunAlarm error: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestAnnounceStep(t *testing.T) {
+	until, err := routine.ParseClock("10:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		given routine.Step
+		want  string
+	}{
+		{"minutes", routine.Step{Task: timeserver.Task{Name: "break", Range: 5 * time.Minute}}, "break, 5 minutes"},
+		{"one hour", routine.Step{Task: timeserver.Task{Name: "working", Range: time.Hour + time.Second}}, "working, 1 hour 1 second"},
+		{"mixed", routine.Step{Task: timeserver.Task{Name: "talk", Range: 2*time.Hour + 30*time.Minute}}, "talk, 2 hours 30 minutes"},
+		{"zero", routine.Step{Task: timeserver.Task{Name: "end"}}, "end, 0 seconds"},
+		{"until", routine.Step{Task: timeserver.Task{Name: "keynote"}, Until: &until}, "keynote, until 10:30"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(routine.AnnounceStep(tt.given), tt.want); diff != "" {
+				t.Errorf("announce text: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	until, err := routine.ParseClock("10:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	type want struct {
+		task timeserver.Task
+		end  time.Time
+	}
+	tests := []struct {
+		name  string
+		step  routine.Step
+		start time.Time
+		want  want
+	}{
+		{
+			"range on time",
+			routine.Step{Task: timeserver.Task{Name: "break", Range: 5 * time.Minute}},
+			cursor,
+			want{timeserver.Task{Name: "break", Range: 5 * time.Minute}, cursor.Add(5 * time.Minute)},
+		},
+		{
+			"range late",
+			routine.Step{Task: timeserver.Task{Name: "break", Range: 5 * time.Minute}},
+			cursor.Add(2 * time.Minute),
+			want{timeserver.Task{Name: "break", Range: 5 * time.Minute, Drift: 2 * time.Minute}, cursor.Add(5 * time.Minute)},
+		},
+		{
+			"until late",
+			routine.Step{Task: timeserver.Task{Name: "keynote"}, Until: &until},
+			cursor.Add(10 * time.Minute),
+			want{timeserver.Task{Name: "keynote", Range: 20 * time.Minute, Drift: 10 * time.Minute}, cursor.Add(30 * time.Minute)},
+		},
+		{
+			"until over",
+			routine.Step{Task: timeserver.Task{Name: "keynote"}, Until: &until},
+			cursor.Add(40 * time.Minute),
+			want{timeserver.Task{Name: "keynote", Drift: 40 * time.Minute}, cursor.Add(30 * time.Minute)},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			task, end := routine.Schedule(tt.step, cursor, tt.start)
+			if diff := cmp.Diff(want{task, end}, tt.want, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestRunAlarm_Warning(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	out := new(bytes.Buffer)
+	err := routine.RunAlarm(
+		r,
+		out,
+		routine.Step{
+			Task: timeserver.Task{
+				Range:    time.Millisecond * 100,
+				Warnings: []time.Duration{time.Millisecond * 50},
+			},
+			Warning: sound.Config{Tone: "440hz:100ms"},
+		},
+		routine.Config{Template: "{{.Name}} {{.Status}} {{.Progress}}"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "alarm warning 50\nalarm finish 100\n"
+	if diff := cmp.Diff(out.String(), want); diff != "" {
+		t.Errorf("routine.RunAlarm output: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestRunAlarm_Format(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	out := new(bytes.Buffer)
+	err := routine.RunAlarm(
+		r,
+		out,
+		routine.Step{Task: timeserver.Task{Name: "tea", Range: time.Millisecond}},
+		routine.Config{Format: "text"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(out.String(), "tea finish\n"); diff != "" {
+		t.Errorf("routine.RunAlarm output: given(-), want(+)\n%s\n", diff)
+	}
+
+	err = routine.RunAlarm(
+		testutil.MockIn("get\n"),
+		out,
+		routine.Step{},
+		routine.Config{Format: "unknown"},
+	)
+	if diff := cmp.Diff(err, output.ErrUnknownFormat, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("routine.RunAlarm error: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestRunAlarm_Capabilities(t *testing.T) {
+	const errLine = "tea error 1m0s: 'capabilities' is not available in current status: output has no handshake message\n"
+	tests := []struct {
+		name    string
+		given   bool
+		want    string
+		wantErr error
+	}{
+		{"continue", false, errLine + "tea stop 1m0s\n", nil},
+		{"strict", true, errLine, timeserver.ErrInvalidState},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			out := new(bytes.Buffer)
+			err := routine.RunAlarm(
+				testutil.MockIn("capabilities\nstop\n"),
+				out,
+				routine.Step{Task: timeserver.Task{Name: "tea", Range: time.Minute}},
+				routine.Config{Format: "text", Strict: tt.given},
+			)
+			if diff := cmp.Diff(err, tt.wantErr, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("routine.RunAlarm error: given(-), want(+)\n%s\n", diff)
+			}
+			if diff := cmp.Diff(out.String(), tt.want); diff != "" {
+				t.Errorf("routine.RunAlarm output: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestRunAlarm_OnEOF(t *testing.T) {
+	type given struct {
+		policy   timeserver.EOFPolicy
+		command  string
+		duration time.Duration
 	}
 	tests := []struct {
 		name    string
 		given   given
+		want    string
 		wantErr error
 	}{
-		{"stop", given{"stop\n", time.Second}, nil},
-		{"finish", given{"get\n", 0}, nil},
-		{"bad command error", given{"unknown\n", time.Second}, timeserver.ErrUnknownCommand},
+		{"stop", given{timeserver.StopOnEOF, "get\n", time.Minute}, "alarm running\nalarm error: EOF\n", io.EOF},
+		{"continue", given{timeserver.ContinueOnEOF, "", time.Millisecond}, "alarm finish\n", nil},
+		{"bad policy", given{"ignore", "get\n", time.Minute}, "", timeserver.ErrBadEOFPolicy},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			err := routine.RunAlarm(testutil.MockIn(tt.given.command), ioutil.Discard, tt.given.duration, "dummy", false)
+			out := new(bytes.Buffer)
+			err := routine.RunAlarm(
+				testutil.MockIn(tt.given.command),
+				out,
+				routine.Step{Task: timeserver.Task{Range: tt.given.duration}},
+				routine.Config{Template: "{{.Name}} {{.Status}}{{with .Error}}: {{.}}{{end}}", OnEOF: tt.given.policy},
+			)
 			if diff := cmp.Diff(err, tt.wantErr, cmpopts.EquateErrors()); diff != "" {
 				t.Errorf("routine.RunAlarm error: given(-), want(+)\n%s\n", diff)
 			}
+			if diff := cmp.Diff(out.String(), tt.want); diff != "" {
+				t.Errorf("routine.RunAlarm output: given(-), want(+)\n%s\n", diff)
+			}
 		})
 	}
 }
+
+func TestRunRoutine_Signal(t *testing.T) {
+	commands := make(chan timeserver.Command)
+	reload := make(chan routine.Routine, 1)
+	reload <- routine.Routine{
+		{Task: timeserver.Task{Range: time.Minute, Name: "reloaded"}},
+	}
+	go func() {
+		commands <- timeserver.ToggleCommand
+		commands <- timeserver.StopCommand
+	}()
+
+	r, w := io.Pipe()
+	defer w.Close()
+	out := new(bytes.Buffer)
+	err := routine.RunRoutine(
+		r,
+		out,
+		routine.Routine{{Task: timeserver.Task{Range: time.Minute, Name: "first"}}},
+		routine.Config{
+			Template: "{{.Name}} {{.Status}} {{.Total}}",
+			Commands: commands,
+			Reload:   reload,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(out.String(), "reloaded pause 1\nreloaded stop 1\n"); diff != "" {
+		t.Errorf("routine.RunRoutine output: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestRunRoutine_Input(t *testing.T) {
+	out := new(bytes.Buffer)
+	err := routine.RunRoutine(
+		testutil.MockIn("next\nget\nstop\n"),
+		out,
+		routine.Routine{
+			{Task: timeserver.Task{Index: 1, Range: time.Minute, Name: "first"}},
+			{Task: timeserver.Task{Index: 2, Range: time.Minute, Name: "second"}},
+		},
+		routine.Config{Template: "{{.Name}} {{.Status}}"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "first finish\nsecond running\nsecond stop\n"
+	if diff := cmp.Diff(out.String(), want); diff != "" {
+		t.Errorf("routine.RunRoutine output: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+// waitGoroutines fails when the number of goroutines does not go back to n.
+func waitGoroutines(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("goroutine leak: %d > %d\n%s", runtime.NumGoroutine(), n, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestRunRoutineContext(t *testing.T) {
+	n := runtime.NumGoroutine()
+	r, w := io.Pipe()
+	out := new(bytes.Buffer)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	err := routine.RunRoutineContext(
+		ctx,
+		r,
+		out,
+		routine.Routine{
+			{Task: timeserver.Task{Index: 1, Range: time.Minute, Name: "first"}},
+			{Task: timeserver.Task{Index: 2, Range: time.Minute, Name: "second"}},
+		},
+		routine.Config{Template: "{{.Name}} {{.Status}}", Loop: true},
+	)
+	if diff := cmp.Diff(err, context.DeadlineExceeded, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("routine.RunRoutineContext error: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(out.String(), "first stop\n"); diff != "" {
+		t.Errorf("routine.RunRoutineContext output: given(-), want(+)\n%s\n", diff)
+	}
+	w.Close()
+	waitGoroutines(t, n)
+}
+
+func TestRunAlarmContext(t *testing.T) {
+	n := runtime.NumGoroutine()
+	r, w := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*50, cancel)
+	err := routine.RunAlarmContext(
+		ctx,
+		r,
+		ioutil.Discard,
+		routine.Step{Task: timeserver.Task{Range: time.Millisecond * 10}},
+		routine.Config{Loop: true, Tick: time.Millisecond},
+	)
+	if diff := cmp.Diff(err, context.Canceled, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("routine.RunAlarmContext error: given(-), want(+)\n%s\n", diff)
+	}
+	w.Close()
+	waitGoroutines(t, n)
+}
+
+func TestLogDrift(t *testing.T) {
+	until, err := routine.ParseClock("10:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		step  routine.Step
+		drift time.Duration
+		want  string
+	}{
+		{"on time", routine.Step{}, time.Millisecond * 10, ""},
+		{"range", routine.Step{}, time.Second * 2, "level=info"},
+		{"until", routine.Step{Until: &until}, time.Second * 2, "level=warn"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			buf := new(bytes.Buffer)
+			ctx := log.NewContext(context.Background(), log.New(buf, log.DebugLevel, log.TextFormat))
+			routine.LogDrift(ctx, tt.step, timeserver.Task{Drift: tt.drift})
+			if tt.want == "" && buf.Len() > 0 || !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("given %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+// waitAlarm plays sound until ctx is done like long repeated sound.
+type waitAlarm struct{ testutil.MockAlarm }
+
+func (*waitAlarm) PlayContext(ctx context.Context) { <-ctx.Done() }
+
+func TestRunAlarmContext_FinalPlay(t *testing.T) {
+	restore := routine.SetNewAlarm(func(sound.Config) (sound.Player, error) { return &waitAlarm{}, nil })
+	defer restore()
+	r, w := io.Pipe()
+	defer w.Close()
+	out := new(bytes.Buffer)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*50, cancel)
+	err := routine.RunAlarmContext(
+		ctx,
+		r,
+		out,
+		routine.Step{Task: timeserver.Task{Range: time.Millisecond * 10}},
+		routine.Config{Template: "{{.Name}} {{.Status}}"},
+	)
+	if diff := cmp.Diff(err, context.Canceled, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("routine.RunAlarmContext error: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(out.String(), "alarm finish\n"); diff != "" {
+		t.Errorf("routine.RunAlarmContext output: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestRunRoutine_Subscribers(t *testing.T) {
+	var finished []string
+	err := routine.RunRoutine(
+		testutil.MockIn("get\nnext\nnext\n"),
+		ioutil.Discard,
+		routine.Routine{
+			{Task: timeserver.Task{Index: 1, Range: time.Minute, Name: "first"}},
+			{Task: timeserver.Task{Index: 2, Range: time.Minute, Name: "second"}},
+		},
+		routine.Config{
+			Subscribers: []routine.Subscriber{{
+				Handler: timeserver.HandlerFunc(func(r timeserver.Result) {
+					finished = append(finished, r.Task.Name)
+				}),
+				Statuses: []timeserver.Status{timeserver.FinishStatus},
+			}},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(finished, []string{"first", "second"}); diff != "" {
+		t.Errorf("finished tasks: given(-), want(+)\n%s\n", diff)
+	}
+}
@@ -0,0 +1,106 @@
+package routine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/komem3/goalarm/internal/sound"
+)
+
+var (
+	ErrNonPositiveRange = errors.New("range must be positive")
+	ErrDuplicateIndex   = errors.New("index is duplicated")
+	ErrNonPositiveWarn  = errors.New("warning must be positive")
+	ErrRangeAndUntil    = errors.New("range and until can not be used together")
+)
+
+// Validate checks ranges, warnings and indexes of steps.
+// Index 0 means the order of routine, so only non-zero indexes must be unique.
+// Step with until must not have range.
+func (r Routine) Validate() error {
+	indexes := make(map[int]int, len(r))
+	for i, step := range r {
+		if step.Until != nil && step.Range != 0 {
+			return fmt.Errorf("step %d(%s): %w", i+1, step.Name, ErrRangeAndUntil)
+		}
+		if step.Until == nil && step.Range <= 0 {
+			return fmt.Errorf("step %d(%s): %s: %w", i+1, step.Name, step.Range, ErrNonPositiveRange)
+		}
+		for _, w := range step.Warnings {
+			if w <= 0 {
+				return fmt.Errorf("step %d(%s): %s: %w", i+1, step.Name, w, ErrNonPositiveWarn)
+			}
+		}
+		if step.Index == 0 {
+			continue
+		}
+		if j, ok := indexes[step.Index]; ok {
+			return fmt.Errorf("step %d(%s): index %d of step %d: %w", i+1, step.Name, step.Index, j+1, ErrDuplicateIndex)
+		}
+		indexes[step.Index] = i
+	}
+	return nil
+}
+
+// ValidateSound checks sounds and warning sounds of every step without speaker.
+func (r Routine) ValidateSound(snd sound.Config) error {
+	for i, step := range r {
+		if err := sound.Validate(snd.Override(step.Sound)); err != nil {
+			return fmt.Errorf("step %d(%s): sound: %w", i+1, step.Name, err)
+		}
+		if len(step.Warning.Files) == 0 && step.Warning.Tone == "" {
+			continue
+		}
+		if err := sound.Validate(snd.Override(step.Warning)); err != nil {
+			return fmt.Errorf("step %d(%s): warning sound: %w", i+1, step.Name, err)
+		}
+	}
+	return nil
+}
+
+// sorted returns routine sorted by index. Steps of same index keep the order.
+func (r Routine) sorted() Routine {
+	s := make(Routine, len(r))
+	copy(s, r)
+	sort.SliceStable(s, func(i, j int) bool {
+		return s[i].Index < s[j].Index
+	})
+	return s
+}
+
+// TimelineStep is the step of routine on wall clock.
+type TimelineStep struct {
+	Loop  int
+	Index int
+	Total int
+	Name  string
+	Start time.Time
+	End   time.Time
+	// NextDay reports that the until clock has passed at Start, so the step ends on the next day.
+	NextDay bool
+}
+
+// Timeline returns steps of routine started at start in running order.
+// Routine is expanded loops times. Until steps end at the next clock on the way.
+func Timeline(r Routine, start time.Time, loops int) []TimelineStep {
+	r = r.sorted()
+	timeline := make([]TimelineStep, 0, len(r)*loops)
+	for loop := 0; loop < loops; loop++ {
+		for i, step := range r {
+			end := step.end(start)
+			timeline = append(timeline, TimelineStep{
+				Loop:    loop,
+				Index:   i + 1,
+				Total:   len(r),
+				Name:    step.Name,
+				Start:   start,
+				End:     end,
+				NextDay: step.Until != nil && step.Until.Passed(start),
+			})
+			start = end
+		}
+	}
+	return timeline
+}
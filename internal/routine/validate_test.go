@@ -0,0 +1,118 @@
+package routine_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/komem3/goalarm/internal/routine"
+	"github.com/komem3/goalarm/internal/sound"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+func TestRoutine_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		given   routine.Routine
+		wantErr error
+	}{
+		{
+			"order of routine",
+			routine.Routine{
+				{Task: timeserver.Task{Range: time.Minute, Name: "working"}},
+				{Task: timeserver.Task{Range: time.Minute, Name: "break"}},
+			},
+			nil,
+		},
+		{
+			"negative range",
+			routine.Routine{
+				{Task: timeserver.Task{Index: 1, Range: -time.Minute, Name: "working"}},
+			},
+			routine.ErrNonPositiveRange,
+		},
+		{
+			"zero warning",
+			routine.Routine{
+				{Task: timeserver.Task{Index: 1, Range: time.Minute, Name: "working", Warnings: []time.Duration{0}}},
+			},
+			routine.ErrNonPositiveWarn,
+		},
+		{
+			"until",
+			routine.Routine{
+				{Task: timeserver.Task{Range: time.Minute, Name: "welcome"}},
+				{Task: timeserver.Task{Name: "keynote"}, Until: clock(t, "10:30")},
+			},
+			nil,
+		},
+		{
+			"range and until",
+			routine.Routine{
+				{Task: timeserver.Task{Range: time.Minute, Name: "keynote"}, Until: clock(t, "10:30")},
+			},
+			routine.ErrRangeAndUntil,
+		},
+		{
+			"duplicate index",
+			routine.Routine{
+				{Task: timeserver.Task{Index: 2, Range: time.Minute, Name: "working"}},
+				{Task: timeserver.Task{Index: 2, Range: time.Minute, Name: "break"}},
+			},
+			routine.ErrDuplicateIndex,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.given.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("given %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRoutine_ValidateSound(t *testing.T) {
+	r := routine.Routine{
+		{Task: timeserver.Task{Range: time.Minute, Name: "working"}},
+		{
+			Task:    timeserver.Task{Range: time.Minute, Name: "break"},
+			Warning: sound.Config{Tone: "bad"},
+		},
+	}
+	err := r.ValidateSound(sound.Config{Tone: "440hz:100ms"})
+	if diff := cmp.Diff(err.Error(), "step 2(break): warning sound: 'bad' is bad tone format"); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	start := time.Date(2021, 1, 1, 23, 30, 0, 0, time.UTC)
+	r := routine.Routine{
+		{Task: timeserver.Task{Index: 2, Range: time.Minute * 5, Name: "break"}},
+		{Task: timeserver.Task{Index: 1, Range: time.Minute * 25, Name: "working"}},
+	}
+	want := []routine.TimelineStep{
+		{Loop: 0, Index: 1, Total: 2, Name: "working", Start: start, End: start.Add(time.Minute * 25)},
+		{Loop: 0, Index: 2, Total: 2, Name: "break", Start: start.Add(time.Minute * 25), End: start.Add(time.Minute * 30)},
+		{Loop: 1, Index: 1, Total: 2, Name: "working", Start: start.Add(time.Minute * 30), End: start.Add(time.Minute * 55)},
+		{Loop: 1, Index: 2, Total: 2, Name: "break", Start: start.Add(time.Minute * 55), End: start.Add(time.Minute * 60)},
+	}
+	if diff := cmp.Diff(routine.Timeline(r, start, 2), want); diff != "" {
+		t.Errorf("given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(r[0].Name, "break"); diff != "" {
+		t.Errorf("routine should not be sorted: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func clock(t *testing.T, s string) *routine.Clock {
+	t.Helper()
+	c, err := routine.ParseClock(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &c
+}
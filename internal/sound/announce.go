@@ -0,0 +1,93 @@
+package sound
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/wav"
+	"github.com/komem3/goalarm/internal/log"
+)
+
+var ErrNoAnnounceCommand = errors.New("no announce command")
+
+// announcement plays bell and then speaks text by external synthesizer command.
+type announcement struct {
+	command []string
+	text    string
+	bell    Player
+	effect  Effect
+	out     output
+}
+
+var _ Player = (*announcement)(nil)
+
+// NewAnnouncement returns player which plays bell and then speaks text by c.Announce command.
+// The command reads text from stdin and writes wav to stdout. e.g. "espeak --stdout"
+// When the command is missing or fails, only bell is played.
+func NewAnnouncement(c Config, text string, bell Player) Player {
+	if c.Silent {
+		return Null{}
+	}
+	a := &announcement{
+		command: strings.Fields(c.Announce),
+		text:    text,
+		bell:    bell,
+		effect:  Effect{Volume: c.Volume},
+		out:     speakerOutput{},
+	}
+	if c.WavOut != "" {
+		a.out = openWavOutput(c.WavOut)
+	}
+	return a
+}
+
+func (a *announcement) synthesize(ctx context.Context) (*Alarm, error) {
+	if len(a.command) == 0 {
+		return nil, ErrNoAnnounceCommand
+	}
+	log.FromContext(ctx).Debugf("announce: %s", a.text)
+	cmd := exec.CommandContext(ctx, a.command[0], a.command[1:]...)
+	cmd.Stdin = strings.NewReader(a.text)
+	b, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("announce %s: %w", a.command[0], err)
+	}
+	streamer, format, err := wav.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("announce %s: %w", a.command[0], err)
+	}
+	buffer := beep.NewBuffer(outputFormat)
+	buffer.Append(resample(format.SampleRate, streamer))
+	alarm := &Alarm{
+		buffer: buffer,
+		effect: a.effect,
+		out:    a.out,
+	}
+	return alarm, alarm.out.init()
+}
+
+func (a *announcement) Play() {
+	go a.PlayWait()
+}
+
+func (a *announcement) PlayWait() {
+	a.PlayContext(context.Background())
+}
+
+func (a *announcement) PlayContext(ctx context.Context) {
+	a.bell.PlayContext(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	alarm, err := a.synthesize(ctx)
+	if err != nil {
+		log.FromContext(ctx).Warnf("play only bell: %v", err)
+		return
+	}
+	alarm.PlayContext(ctx)
+}
@@ -0,0 +1,90 @@
+package sound_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/wav"
+	"github.com/google/go-cmp/cmp"
+	"github.com/komem3/goalarm/internal/sound"
+)
+
+type countPlayer struct {
+	count int
+}
+
+func (c *countPlayer) Play()                       { c.count++ }
+func (c *countPlayer) PlayWait()                   { c.count++ }
+func (c *countPlayer) PlayContext(context.Context) { c.count++ }
+
+func TestAnnouncement(t *testing.T) {
+	dir := t.TempDir()
+	voice := filepath.Join(dir, "voice.wav")
+	{
+		f, err := os.Create(voice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		format := beep.Format{SampleRate: 22050, NumChannels: 1, Precision: 2}
+		if err := wav.Encode(f, beep.Take(11025, beep.Silence(-1)), format); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	// synthesizer stub which consumes text and writes voice.wav
+	synth := filepath.Join(dir, "synth.sh")
+	script := fmt.Sprintf("#!/bin/sh\ncat > %s\ncat %s\n", filepath.Join(dir, "text"), voice)
+	if err := ioutil.WriteFile(synth, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		command     string
+		wantSamples int
+	}{
+		{"speak", synth, int(sound.SampleRate) / 2},
+		{"missing command", filepath.Join(dir, "not_found"), 0},
+		{"not wav", "cat", 0},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "out.wav")
+			bell := new(countPlayer)
+			p := sound.NewAnnouncement(sound.Config{Announce: tt.command, WavOut: out}, "break, 5 minutes", bell)
+			p.PlayWait()
+
+			if diff := cmp.Diff(bell.count, 1); diff != "" {
+				t.Errorf("bell count: given(-), want(+)\n%s\n", diff)
+			}
+			if tt.wantSamples == 0 {
+				return
+			}
+			text, err := ioutil.ReadFile(filepath.Join(dir, "text"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(string(text), "break, 5 minutes"); diff != "" {
+				t.Errorf("spoken text: given(-), want(+)\n%s\n", diff)
+			}
+			f, err := os.Open(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			s, _, err := wav.Decode(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(s.Len(), tt.wantSamples); diff != "" {
+				t.Errorf("wav length: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
@@ -1,32 +1,146 @@
 package sound
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/faiface/beep"
+	"github.com/faiface/beep/flac"
 	"github.com/faiface/beep/mp3"
-	"github.com/faiface/beep/speaker"
+	"github.com/faiface/beep/vorbis"
 	"github.com/faiface/beep/wav"
 	"github.com/komem3/goalarm/internal/log"
 )
 
 type Alarm struct {
 	buffer *beep.Buffer
+	effect Effect
+	out    output
 }
 
+// Player plays sound.
+// Play does not wait for the end of sound. PlayWait waits for it.
+// PlayContext waits for it, and stops the sound when ctx is done.
 type Player interface {
 	Play()
 	PlayWait()
+	PlayContext(ctx context.Context)
+}
+
+// Config selects the sound of alarm.
+// Files has priority over Tone. When both are empty, DefaultTone is used.
+type Config struct {
+	// Files are paths of sound file. Directory and glob pattern are expanded.
+	// When there are some files, one of them is played in Order each time.
+	Files []string
+	Order Order
+	Tone  string
+	// Silent plays nothing and does not use speaker.
+	Silent bool
+	// WavOut is path of wav file which sound is written to instead of speaker.
+	WavOut string
+	// Announce is synthesizer command used by NewAnnouncement.
+	Announce string
+	Effect
 }
 
 var ErrUnsuportExt = fmt.Errorf("unsuported ext")
 
-func NewAalarm(path string) (Player, error) {
-	log.Printf("sound file is %s\n", path)
+func New(c Config) (Player, error) {
+	if err := c.Effect.validate(); err != nil {
+		return nil, err
+	}
+	if err := c.Order.validate(); err != nil {
+		return nil, err
+	}
+	if c.Silent {
+		log.Debugf("silent mode")
+		return Null{}, nil
+	}
+	alarms, files, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range alarms {
+		if err := c.setup(a); err != nil {
+			return nil, err
+		}
+	}
+	if len(alarms) == 1 {
+		return alarms[0], nil
+	}
+	return newPlaylist(alarms, files, c.Order), nil
+}
+
+// Validate checks c as New does without using speaker.
+func Validate(c Config) error {
+	if err := c.Effect.validate(); err != nil {
+		return err
+	}
+	if err := c.Order.validate(); err != nil {
+		return err
+	}
+	if c.Silent {
+		return nil
+	}
+	_, _, err := c.load()
+	return err
+}
+
+// load decodes sound files or generates tone.
+func (c Config) load() (alarms []*Alarm, files []string, err error) {
+	if len(c.Files) == 0 {
+		tone := c.Tone
+		if tone == "" {
+			tone = DefaultTone
+		}
+		a, err := generateTone(tone)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []*Alarm{a}, nil, nil
+	}
+
+	if files, err = expandFiles(c.Files); err != nil {
+		return nil, nil, err
+	}
+	alarms = make([]*Alarm, len(files))
+	for i, file := range files {
+		if alarms[i], err = openFile(file); err != nil {
+			return nil, nil, err
+		}
+	}
+	return alarms, files, nil
+}
+
+// Override returns config overwritten by non zero fields of o.
+// Files and Tone are the source of sound, so both are replaced when o has either of them.
+// Silent and WavOut are not overwritten.
+func (c Config) Override(o Config) Config {
+	if len(o.Files) != 0 || o.Tone != "" {
+		c.Files = o.Files
+		c.Tone = o.Tone
+	}
+	if o.Order != "" {
+		c.Order = o.Order
+	}
+	c.Effect = c.Effect.Override(o.Effect)
+	return c
+}
+
+func (c Config) setup(a *Alarm) error {
+	a.effect = c.Effect
+	if c.WavOut != "" {
+		a.out = openWavOutput(c.WavOut)
+	}
+	return a.out.init()
+}
+
+func openFile(path string) (*Alarm, error) {
+	log.Debugf("sound file is %s", path)
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -40,32 +154,45 @@ func NewAalarm(path string) (Player, error) {
 		streamer, format, err = mp3.Decode(f)
 	case ".wav":
 		streamer, format, err = wav.Decode(f)
+	case ".flac":
+		streamer, format, err = flac.Decode(f)
+	case ".ogg", ".oga":
+		streamer, format, err = vorbis.Decode(f)
 	default:
 		err = fmt.Errorf("open %s: %w", filepath.Ext(path), ErrUnsuportExt)
 	}
 	if err != nil {
 		return nil, err
 	}
-	speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10))
-
-	buffer := beep.NewBuffer(format)
-	buffer.Append(streamer)
+	buffer := beep.NewBuffer(outputFormat)
+	buffer.Append(resample(format.SampleRate, streamer))
 	streamer.Close()
 	return &Alarm{
 		buffer: buffer,
+		out:    speakerOutput{},
 	}, nil
 }
 
 func (a *Alarm) Play() {
-	log.Printf("async play sound\n")
-	speaker.Play(a.buffer.Streamer(0, a.buffer.Len()))
+	log.Debugf("async play sound")
+	a.out.play(a.effect.apply(a.buffer))
 }
 
 func (a *Alarm) PlayWait() {
-	log.Printf("wait play sound\n")
+	a.PlayContext(context.Background())
+}
+
+func (a *Alarm) PlayContext(ctx context.Context) {
+	log.FromContext(ctx).Debugf("wait play sound")
 	done := make(chan struct{})
-	speaker.Play(beep.Seq(a.buffer.Streamer(0, a.buffer.Len()), beep.Callback(func() {
-		done <- struct{}{}
-	})))
-	<-done
+	ctrl := &beep.Ctrl{Streamer: beep.Seq(a.effect.apply(a.buffer), beep.Callback(func() {
+		close(done)
+	}))}
+	a.out.play(ctrl)
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.FromContext(ctx).Debugf("stop sound: %v", ctx.Err())
+		a.out.stop(ctrl)
+	}
 }
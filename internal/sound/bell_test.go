@@ -17,6 +17,8 @@ func TestAlarm(t *testing.T) {
 	}{
 		{"mp3", "./sample.mp3", "mp3: EOF"},
 		{"wav", "./sample.wav", "wav: EOF"},
+		{"flac", "./sample.flac", "flac: EOF"},
+		{"ogg", "./sample.ogg", "ogg/vorbis: EOF"},
 		{"not suport format", "./sample.mp4", "open .mp4: unsuported ext"},
 	}
 	for _, tt := range tests {
@@ -27,10 +29,40 @@ func TestAlarm(t *testing.T) {
 				t.Fatal(err)
 			}
 			defer os.Remove(tt.given)
-			_, err := sound.NewAalarm(tt.given)
+			_, err := sound.New(sound.Config{Files: []string{tt.given}})
 			if diff := cmp.Diff(err.Error(), tt.wantErr); diff != "" {
 				t.Errorf("Alarm error: given(-), want(+)\n%s\n", diff)
 			}
 		})
 	}
 }
+
+func TestConfig_Override(t *testing.T) {
+	base := sound.Config{Files: []string{"bell.mp3"}, Order: sound.RandomOrder, Silent: true}
+	tests := []struct {
+		name  string
+		given sound.Config
+		want  sound.Config
+	}{
+		{"empty", sound.Config{}, base},
+		{
+			"tone replaces files",
+			sound.Config{Tone: "440hz:100ms"},
+			sound.Config{Tone: "440hz:100ms", Order: sound.RandomOrder, Silent: true},
+		},
+		{
+			"files",
+			sound.Config{Files: []string{"chime.wav"}},
+			sound.Config{Files: []string{"chime.wav"}, Order: sound.RandomOrder, Silent: true},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(base.Override(tt.given), tt.want); diff != "" {
+				t.Errorf("given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package sound
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+// Effect changes how a sound is played.
+// Zero value plays the sound once at original volume.
+type Effect struct {
+	// Volume is percent("50%") or decibel("-6db").
+	Volume string
+	FadeIn time.Duration
+	// Repeat is play count of the sound. 0 is same as 1.
+	Repeat   int
+	Interval time.Duration
+	// MaxDuration cuts the sound off. 0 is unlimited.
+	MaxDuration time.Duration
+}
+
+var (
+	ErrBadVolume      = errors.New("bad volume format")
+	ErrNegativeEffect = errors.New("must not be negative")
+)
+
+// Override returns effect overwritten by non zero fields of o.
+func (e Effect) Override(o Effect) Effect {
+	if o.Volume != "" {
+		e.Volume = o.Volume
+	}
+	if o.FadeIn != 0 {
+		e.FadeIn = o.FadeIn
+	}
+	if o.Repeat != 0 {
+		e.Repeat = o.Repeat
+	}
+	if o.Interval != 0 {
+		e.Interval = o.Interval
+	}
+	if o.MaxDuration != 0 {
+		e.MaxDuration = o.MaxDuration
+	}
+	return e
+}
+
+func (e Effect) validate() error {
+	if _, err := parseVolume(e.Volume); err != nil {
+		return err
+	}
+	switch {
+	case e.FadeIn < 0:
+		return fmt.Errorf("fade in %s %w", e.FadeIn, ErrNegativeEffect)
+	case e.Repeat < 0:
+		return fmt.Errorf("repeat %d %w", e.Repeat, ErrNegativeEffect)
+	case e.Interval < 0:
+		return fmt.Errorf("interval %s %w", e.Interval, ErrNegativeEffect)
+	case e.MaxDuration < 0:
+		return fmt.Errorf("max duration %s %w", e.MaxDuration, ErrNegativeEffect)
+	}
+	return nil
+}
+
+// parseVolume converts volume to decibel.
+func parseVolume(v string) (db float64, err error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	switch {
+	case v == "":
+		return 0, nil
+	case strings.HasSuffix(v, "%"):
+		p, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+		if err != nil || p < 0 {
+			return 0, fmt.Errorf("'%s' is %w", v, ErrBadVolume)
+		}
+		return 20 * math.Log10(p/100), nil
+	case strings.HasSuffix(v, "db"):
+		db, err := strconv.ParseFloat(strings.TrimSuffix(v, "db"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is %w", v, ErrBadVolume)
+		}
+		return db, nil
+	default:
+		return 0, fmt.Errorf("'%s' is %w", v, ErrBadVolume)
+	}
+}
+
+// apply builds streamer of buffer with effect. e must be validated.
+func (e Effect) apply(buffer *beep.Buffer) beep.Streamer {
+	sr := buffer.Format().SampleRate
+
+	repeat := e.Repeat
+	if repeat < 1 {
+		repeat = 1
+	}
+	streamers := make([]beep.Streamer, 0, repeat*2)
+	for i := 0; i < repeat; i++ {
+		if i > 0 && e.Interval > 0 {
+			streamers = append(streamers, beep.Silence(sr.N(e.Interval)))
+		}
+		streamers = append(streamers, buffer.Streamer(0, buffer.Len()))
+	}
+	s := beep.Seq(streamers...)
+
+	if e.FadeIn > 0 {
+		s = fadeIn(sr.N(e.FadeIn), s)
+	}
+	if db, _ := parseVolume(e.Volume); db != 0 {
+		s = &effects.Volume{
+			Streamer: s,
+			Base:     10,
+			Volume:   db / 20,
+			Silent:   math.IsInf(db, -1),
+		}
+	}
+	if e.MaxDuration > 0 {
+		s = beep.Take(sr.N(e.MaxDuration), s)
+	}
+	return s
+}
+
+func fadeIn(length int, s beep.Streamer) beep.Streamer {
+	var pos int
+	return beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		n, ok = s.Stream(samples)
+		for i := range samples[:n] {
+			if pos >= length {
+				break
+			}
+			gain := float64(pos) / float64(length)
+			samples[i][0] *= gain
+			samples[i][1] *= gain
+			pos++
+		}
+		return n, ok
+	})
+}
@@ -0,0 +1,116 @@
+package sound_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/komem3/goalarm/internal/sound"
+)
+
+func TestParseVolume(t *testing.T) {
+	type want struct {
+		db  float64
+		err error
+	}
+	tests := []struct {
+		name  string
+		given string
+		want  want
+	}{
+		{"empty", "", want{0, nil}},
+		{"full percent", "100%", want{0, nil}},
+		{"ten percent", "10%", want{-20, nil}},
+		{"mute", "0%", want{math.Inf(-1), nil}},
+		{"decibel", "-6dB", want{-6, nil}},
+		{"no unit", "50", want{0, sound.ErrBadVolume}},
+		{"negative percent", "-5%", want{0, sound.ErrBadVolume}},
+		{"bad decibel", "loud db", want{0, sound.ErrBadVolume}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			db, err := sound.ParseVolume(tt.given)
+			if diff := cmp.Diff(err, tt.want.err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("parseVolume error: given(-), want(+)\n%s\n", diff)
+			}
+			if diff := cmp.Diff(db, tt.want.db, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("parseVolume: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestEffect_Override(t *testing.T) {
+	base := sound.Effect{
+		Volume:   "50%",
+		FadeIn:   time.Second,
+		Repeat:   2,
+		Interval: time.Second,
+	}
+	given := base.Override(sound.Effect{Volume: "-3db", MaxDuration: time.Minute})
+	want := sound.Effect{
+		Volume:      "-3db",
+		FadeIn:      time.Second,
+		Repeat:      2,
+		Interval:    time.Second,
+		MaxDuration: time.Minute,
+	}
+	if diff := cmp.Diff(given, want); diff != "" {
+		t.Errorf("override: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestEffect_Samples(t *testing.T) {
+	const length = 44100 // 1 second
+	tests := []struct {
+		name  string
+		given sound.Effect
+		want  int
+	}{
+		{"zero", sound.Effect{}, length},
+		{"repeat", sound.Effect{Repeat: 3}, length * 3},
+		{"repeat with interval", sound.Effect{Repeat: 3, Interval: time.Second}, length * 5},
+		{"max duration", sound.Effect{Repeat: 3, MaxDuration: 1500 * time.Millisecond}, length * 3 / 2},
+		{"fade in and volume", sound.Effect{FadeIn: 2 * time.Second, Volume: "50%"}, length},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(sound.EffectSamples(tt.given, length), tt.want); diff != "" {
+				t.Errorf("effect samples: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestValidate_Effect(t *testing.T) {
+	tests := []struct {
+		name  string
+		given sound.Effect
+		want  string
+	}{
+		{"negative fade in", sound.Effect{FadeIn: -time.Second}, "fade in -1s must not be negative"},
+		{"negative repeat", sound.Effect{Repeat: -1}, "repeat -1 must not be negative"},
+		{"negative interval", sound.Effect{Interval: -time.Second}, "interval -1s must not be negative"},
+		{"negative max duration", sound.Effect{MaxDuration: -time.Second}, "max duration -1s must not be negative"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := sound.Validate(sound.Config{Silent: true, Effect: tt.given})
+			if !errors.Is(err, sound.ErrNegativeEffect) {
+				t.Fatalf("given %v, want %v", err, sound.ErrNegativeEffect)
+			}
+			if diff := cmp.Diff(err.Error(), tt.want); diff != "" {
+				t.Errorf("given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
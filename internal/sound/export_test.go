@@ -0,0 +1,78 @@
+package sound
+
+import (
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+type Tone struct {
+	Freq   float64
+	Length time.Duration
+	Count  int
+}
+
+func ParseTone(spec string) (Tone, error) {
+	t, err := parseTone(spec)
+	return Tone{Freq: t.freq, Length: t.length, Count: t.count}, err
+}
+
+func ToneSamples(spec string) int {
+	t, _ := parseTone(spec)
+	buffer := beep.NewBuffer(outputFormat)
+	buffer.Append(t.streamer(SampleRate))
+	return buffer.Len()
+}
+
+func ParseVolume(v string) (float64, error) {
+	return parseVolume(v)
+}
+
+func EffectSamples(e Effect, samples int) int {
+	buffer := beep.NewBuffer(outputFormat)
+	buffer.Append(beep.Take(samples, beep.Silence(-1)))
+	result := beep.NewBuffer(outputFormat)
+	result.Append(e.apply(buffer))
+	return result.Len()
+}
+
+func ExpandFiles(paths []string) ([]string, error) {
+	return expandFiles(paths)
+}
+
+// PlaylistPicks returns indexes of sound picked by playlist of size n.
+func PlaylistPicks(n int, order Order, plays int) []int {
+	alarms := make([]*Alarm, n)
+	paths := make([]string, n)
+	index := make(map[*Alarm]int)
+	for i := range alarms {
+		alarms[i] = &Alarm{}
+		index[alarms[i]] = i
+	}
+	p := newPlaylist(alarms, paths, order)
+	picks := make([]int, plays)
+	for i := range picks {
+		picks[i] = index[p.pick()]
+	}
+	return picks
+}
+
+// HoldOutput keeps played sound without consuming it.
+type HoldOutput struct {
+	Ctrl *beep.Ctrl
+}
+
+func (o *HoldOutput) init() error { return nil }
+
+func (o *HoldOutput) play(s beep.Streamer) { o.Ctrl = s.(*beep.Ctrl) }
+
+func (o *HoldOutput) stop(ctrl *beep.Ctrl) { ctrl.Streamer = nil }
+
+func NewHoldTone(spec string, out *HoldOutput) (Player, error) {
+	a, err := generateTone(spec)
+	if err != nil {
+		return nil, err
+	}
+	a.out = out
+	return a, nil
+}
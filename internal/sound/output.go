@@ -0,0 +1,200 @@
+package sound
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+	"github.com/faiface/beep/wav"
+	"github.com/komem3/goalarm/internal/log"
+)
+
+// SampleRate is sample rate of output. All sounds are resampled to it.
+const SampleRate = beep.SampleRate(44100)
+
+const resampleQuality = 4
+
+var outputFormat = beep.Format{
+	SampleRate:  SampleRate,
+	NumChannels: 2,
+	Precision:   2,
+}
+
+// resample converts streamer of sample rate from to SampleRate.
+func resample(from beep.SampleRate, s beep.Streamer) beep.Streamer {
+	if from == SampleRate {
+		return s
+	}
+	log.Debugf("resample: %d Hz to %d Hz", from, SampleRate)
+	return beep.Resample(resampleQuality, from, SampleRate, s)
+}
+
+// output is the destination of sound.
+// Speaker mixes sounds played at the same time, and wav file appends them in order of play.
+// stop stops the sound of ctrl being played.
+type output interface {
+	init() error
+	play(s beep.Streamer)
+	stop(ctrl *beep.Ctrl)
+}
+
+type speakerOutput struct{}
+
+var (
+	speakerOnce sync.Once
+	speakerErr  error
+)
+
+// init initializes speaker only once, because re-initializing stops playing sounds.
+func (speakerOutput) init() error {
+	speakerOnce.Do(func() {
+		log.Debugf("init speaker: %d Hz", SampleRate)
+		if err := speaker.Init(SampleRate, SampleRate.N(time.Second/10)); err != nil {
+			speakerErr = fmt.Errorf("init speaker: %w", err)
+		}
+	})
+	return speakerErr
+}
+
+func (speakerOutput) play(s beep.Streamer) {
+	speaker.Play(s)
+}
+
+func (speakerOutput) stop(ctrl *beep.Ctrl) {
+	speaker.Lock()
+	ctrl.Streamer = nil
+	speaker.Unlock()
+}
+
+// wavHeaderSize is the size of header written by wav.Encode.
+// Sizes of RIFF chunk and data chunk are at offset 4 and 40.
+const wavHeaderSize = 44
+
+// wavOutput renders sound to wav file.
+// Played sounds are appended to the file in order.
+type wavOutput struct {
+	mu   sync.Mutex
+	path string
+	// size is the bytes of data chunk. It is negative before init.
+	size int64
+}
+
+var (
+	wavOutputsMu sync.Mutex
+	wavOutputs   = make(map[string]*wavOutput)
+)
+
+// openWavOutput returns shared output of path.
+func openWavOutput(path string) *wavOutput {
+	wavOutputsMu.Lock()
+	defer wavOutputsMu.Unlock()
+	if o, ok := wavOutputs[path]; ok {
+		return o
+	}
+	o := &wavOutput{path: path, size: -1}
+	wavOutputs[path] = o
+	return o
+}
+
+// init writes empty wav file.
+func (w *wavOutput) init() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size >= 0 {
+		return nil
+	}
+	f, err := os.Create(w.path)
+	if err != nil {
+		return err
+	}
+	if err := wav.Encode(f, beep.Silence(0), outputFormat); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	w.size = 0
+	return nil
+}
+
+func (w *wavOutput) play(s beep.Streamer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.append(s); err != nil {
+		log.Errorf("write %s: %v", w.path, err)
+	}
+}
+
+// stop does nothing, because sound is rendered at once by play.
+func (w *wavOutput) stop(*beep.Ctrl) {}
+
+// append writes samples of s after the data and updates sizes in the header.
+func (w *wavOutput) append(s beep.Streamer) error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	size, err := writeSamples(f, wavHeaderSize+w.size, s)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.size += size
+	if err := writeWavSizes(f, w.size); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeSamples writes samples of s from offset of f, and returns the written bytes.
+func writeSamples(f *os.File, offset int64, s beep.Streamer) (size int64, err error) {
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	bw := bufio.NewWriter(f)
+	samples := make([][2]float64, 512)
+	p := make([]byte, outputFormat.Width())
+	for {
+		n, ok := s.Stream(samples)
+		if !ok {
+			break
+		}
+		for _, sample := range samples[:n] {
+			outputFormat.EncodeSigned(p, sample)
+			if _, err := bw.Write(p); err != nil {
+				return 0, err
+			}
+		}
+		size += int64(n * len(p))
+	}
+	return size, bw.Flush()
+}
+
+func writeWavSizes(f *os.File, dataSize int64) error {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(wavHeaderSize-8+dataSize))
+	if _, err := f.WriteAt(b[:], 4); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(b[:], uint32(dataSize))
+	_, err := f.WriteAt(b[:], wavHeaderSize-4)
+	return err
+}
+
+// Null is a player which plays nothing.
+type Null struct{}
+
+var _ Player = Null{}
+
+func (Null) Play()                       {}
+func (Null) PlayWait()                   {}
+func (Null) PlayContext(context.Context) {}
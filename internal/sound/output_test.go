@@ -0,0 +1,153 @@
+package sound_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/wav"
+	"github.com/google/go-cmp/cmp"
+	"github.com/komem3/goalarm/internal/sound"
+)
+
+func TestNew_WavOut(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.wav")
+	p, err := sound.New(sound.Config{
+		Tone:   "440hz:100ms x2",
+		WavOut: out,
+		Effect: sound.Effect{Repeat: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.PlayWait()
+	p.Play()
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	s, format, err := wav.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// (2 beeps + 1 silence) * 2 repeats * 2 plays
+	if diff := cmp.Diff(s.Len(), format.SampleRate.N(1200*time.Millisecond)); diff != "" {
+		t.Errorf("appended wav length: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestNew_Silent(t *testing.T) {
+	p, err := sound.New(sound.Config{Files: []string{"not_found.mp3"}, Silent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(p, sound.Player(sound.Null{})); diff != "" {
+		t.Errorf("silent player: given(-), want(+)\n%s\n", diff)
+	}
+	p.PlayWait()
+}
+
+func TestNew_Resample(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.wav")
+	out := filepath.Join(dir, "out.wav")
+	{
+		f, err := os.Create(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		format := beep.Format{SampleRate: 22050, NumChannels: 1, Precision: 2}
+		if err := wav.Encode(f, beep.Take(22050, beep.Silence(-1)), format); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+
+	p, err := sound.New(sound.Config{Files: []string{in}, WavOut: out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.PlayWait()
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	s, format, err := wav.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(format.SampleRate, sound.SampleRate); diff != "" {
+		t.Errorf("sample rate: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(s.Len(), int(sound.SampleRate)); diff != "" {
+		t.Errorf("appended wav length: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestNew_ConcurrentPlay(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.wav")
+	var players []sound.Player
+	for _, tone := range []string{"440hz:10ms", "880hz:10ms", "220hz:10ms"} {
+		p, err := sound.New(sound.Config{Tone: tone, WavOut: out})
+		if err != nil {
+			t.Fatal(err)
+		}
+		players = append(players, p)
+	}
+
+	var wg sync.WaitGroup
+	for _, p := range players {
+		wg.Add(1)
+		go func(p sound.Player) {
+			defer wg.Done()
+			p.PlayWait()
+		}(p)
+	}
+	wg.Wait()
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	s, format, err := wav.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(s.Len(), format.SampleRate.N(30*time.Millisecond)); diff != "" {
+		t.Errorf("appended wav length: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestAlarm_PlayContext(t *testing.T) {
+	out := new(sound.HoldOutput)
+	p, err := sound.NewHoldTone("440hz:100ms", out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		p.PlayContext(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("PlayContext does not return after cancel")
+	}
+	if out.Ctrl == nil || out.Ctrl.Streamer != nil {
+		t.Errorf("sound is not stopped: %#v", out.Ctrl)
+	}
+}
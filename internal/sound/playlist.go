@@ -0,0 +1,143 @@
+package sound
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/komem3/goalarm/internal/log"
+)
+
+// Order is selection of sound in playlist.
+type Order string
+
+const (
+	SequentialOrder Order = "sequential"
+	RandomOrder     Order = "random"
+)
+
+var (
+	ErrNoSoundFile = errors.New("no sound file")
+	ErrBadOrder    = errors.New("bad order")
+)
+
+var supportedExts = map[string]bool{
+	".mp3":  true,
+	".wav":  true,
+	".flac": true,
+	".ogg":  true,
+	".oga":  true,
+}
+
+// playlist plays one of alarms each time.
+type playlist struct {
+	mu     sync.Mutex
+	alarms []*Alarm
+	paths  []string
+	order  Order
+	// next is the index of the next sound. In random order, it is the previous one and -1 before the first pick.
+	next int
+	rand *rand.Rand
+}
+
+var _ Player = (*playlist)(nil)
+
+func newPlaylist(alarms []*Alarm, paths []string, order Order) *playlist {
+	p := &playlist{
+		alarms: alarms,
+		paths:  paths,
+		order:  order,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if order == RandomOrder {
+		p.next = -1
+	}
+	return p
+}
+
+func (p *playlist) pick() *Alarm {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	i := p.next
+	switch p.order {
+	case RandomOrder:
+		switch {
+		case p.next < 0:
+			i = p.rand.Intn(len(p.alarms))
+		case len(p.alarms) > 1:
+			// avoid playing same sound in a row
+			i = (p.next + 1 + p.rand.Intn(len(p.alarms)-1)) % len(p.alarms)
+		}
+		p.next = i
+	default:
+		p.next = (p.next + 1) % len(p.alarms)
+	}
+	log.Debugf("pick sound %s", p.paths[i])
+	return p.alarms[i]
+}
+
+func (p *playlist) Play() {
+	p.pick().Play()
+}
+
+func (p *playlist) PlayWait() {
+	p.pick().PlayWait()
+}
+
+func (p *playlist) PlayContext(ctx context.Context) {
+	p.pick().PlayContext(ctx)
+}
+
+func (o Order) validate() error {
+	switch o {
+	case "", SequentialOrder, RandomOrder:
+		return nil
+	default:
+		return fmt.Errorf("'%s' is %w. support %s or %s", o, ErrBadOrder, SequentialOrder, RandomOrder)
+	}
+}
+
+// expandFiles expands directories and glob patterns to sound files.
+func expandFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			infos, err := ioutil.ReadDir(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, info := range infos {
+				if !info.IsDir() && supportedExts[strings.ToLower(filepath.Ext(info.Name()))] {
+					files = append(files, filepath.Join(path, info.Name()))
+				}
+			}
+			continue
+		}
+		if !strings.ContainsAny(path, "*?[") {
+			files = append(files, path)
+			continue
+		}
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		for _, m := range matches {
+			if supportedExts[strings.ToLower(filepath.Ext(m))] {
+				files = append(files, m)
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s: %w", strings.Join(paths, ","), ErrNoSoundFile)
+	}
+	return files, nil
+}
@@ -0,0 +1,99 @@
+package sound_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/komem3/goalarm/internal/sound"
+	"github.com/komem3/goalarm/internal/testutil"
+)
+
+func TestExpandFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.mp3", "a.wav", "c.txt", "d.flac"} {
+		if err := testutil.EmptyFile(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	type want struct {
+		files []string
+		err   error
+	}
+	tests := []struct {
+		name  string
+		given []string
+		want  want
+	}{
+		{
+			"directory",
+			[]string{dir},
+			want{[]string{filepath.Join(dir, "a.wav"), filepath.Join(dir, "b.mp3"), filepath.Join(dir, "d.flac")}, nil},
+		},
+		{
+			"glob",
+			[]string{filepath.Join(dir, "*.mp3"), filepath.Join(dir, "?.wav")},
+			want{[]string{filepath.Join(dir, "b.mp3"), filepath.Join(dir, "a.wav")}, nil},
+		},
+		{
+			"file",
+			[]string{"not_expanded.mp3"},
+			want{[]string{"not_expanded.mp3"}, nil},
+		},
+		{
+			"no match",
+			[]string{filepath.Join(dir, "*.ogg")},
+			want{nil, sound.ErrNoSoundFile},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			files, err := sound.ExpandFiles(tt.given)
+			if diff := cmp.Diff(err, tt.want.err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("expandFiles error: given(-), want(+)\n%s\n", diff)
+			}
+			if diff := cmp.Diff(files, tt.want.files); diff != "" {
+				t.Errorf("expandFiles: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestPlaylist(t *testing.T) {
+	t.Run("sequential", func(t *testing.T) {
+		t.Parallel()
+		given := sound.PlaylistPicks(3, sound.SequentialOrder, 5)
+		if diff := cmp.Diff(given, []int{0, 1, 2, 0, 1}); diff != "" {
+			t.Errorf("sequential picks: given(-), want(+)\n%s\n", diff)
+		}
+	})
+	t.Run("random", func(t *testing.T) {
+		t.Parallel()
+		given := sound.PlaylistPicks(3, sound.RandomOrder, 100)
+		for i := 1; i < len(given); i++ {
+			if given[i] == given[i-1] {
+				t.Fatalf("same sound in a row: %v", given)
+			}
+		}
+	})
+	t.Run("random first", func(t *testing.T) {
+		t.Parallel()
+		firsts := make(map[int]bool)
+		for i := 0; i < 100; i++ {
+			firsts[sound.PlaylistPicks(3, sound.RandomOrder, 1)[0]] = true
+		}
+		if diff := cmp.Diff(firsts, map[int]bool{0: true, 1: true, 2: true}); diff != "" {
+			t.Errorf("first picks: given(-), want(+)\n%s\n", diff)
+		}
+	})
+}
+
+func TestNew_BadOrder(t *testing.T) {
+	_, err := sound.New(sound.Config{Order: "shuffle"})
+	if diff := cmp.Diff(err, sound.ErrBadOrder, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("order error: given(-), want(+)\n%s\n", diff)
+	}
+}
@@ -0,0 +1,99 @@
+package sound
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/komem3/goalarm/internal/log"
+)
+
+// DefaultTone is played when no sound file is given.
+const DefaultTone = "880hz:200ms x3"
+
+const toneAmplitude = 0.3
+
+var ErrBadTone = errors.New("bad tone format")
+
+type tone struct {
+	freq   float64
+	length time.Duration
+	count  int
+}
+
+// generateTone generates a beep pattern.
+// Format of spec is "<frequency>hz:<length> x<count>". e.g. "880hz:200ms x3".
+// A silence of the same length is inserted between beeps.
+func generateTone(spec string) (*Alarm, error) {
+	log.Debugf("sound tone is %s", spec)
+	t, err := parseTone(spec)
+	if err != nil {
+		return nil, err
+	}
+	buffer := beep.NewBuffer(outputFormat)
+	buffer.Append(t.streamer(SampleRate))
+	return &Alarm{
+		buffer: buffer,
+		out:    speakerOutput{},
+	}, nil
+}
+
+func parseTone(spec string) (t tone, err error) {
+	badTone := fmt.Errorf("'%s' is %w", spec, ErrBadTone)
+
+	fields := strings.Fields(strings.ToLower(spec))
+	if len(fields) == 0 || len(fields) > 2 {
+		return t, badTone
+	}
+
+	t.count = 1
+	if len(fields) == 2 {
+		if !strings.HasPrefix(fields[1], "x") {
+			return t, badTone
+		}
+		if t.count, err = strconv.Atoi(fields[1][1:]); err != nil || t.count < 1 {
+			return t, badTone
+		}
+	}
+
+	parts := strings.Split(fields[0], ":")
+	if len(parts) != 2 || !strings.HasSuffix(parts[0], "hz") {
+		return t, badTone
+	}
+	if t.freq, err = strconv.ParseFloat(strings.TrimSuffix(parts[0], "hz"), 64); err != nil || t.freq <= 0 {
+		return t, badTone
+	}
+	if t.length, err = time.ParseDuration(parts[1]); err != nil || t.length <= 0 {
+		return t, badTone
+	}
+	return t, nil
+}
+
+func (t tone) streamer(sr beep.SampleRate) beep.Streamer {
+	n := sr.N(t.length)
+	streamers := make([]beep.Streamer, 0, t.count*2)
+	for i := 0; i < t.count; i++ {
+		if i > 0 {
+			streamers = append(streamers, beep.Silence(n))
+		}
+		streamers = append(streamers, beep.Take(n, sine(sr, t.freq)))
+	}
+	return beep.Seq(streamers...)
+}
+
+func sine(sr beep.SampleRate, freq float64) beep.Streamer {
+	var pos int
+	step := 2 * math.Pi * freq / float64(sr)
+	return beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		for i := range samples {
+			v := math.Sin(step*float64(pos)) * toneAmplitude
+			samples[i] = [2]float64{v, v}
+			pos++
+		}
+		return len(samples), true
+	})
+}
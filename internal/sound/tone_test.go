@@ -0,0 +1,53 @@
+package sound_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/komem3/goalarm/internal/sound"
+)
+
+func TestParseTone(t *testing.T) {
+	type want struct {
+		tone sound.Tone
+		err  error
+	}
+	tests := []struct {
+		name  string
+		given string
+		want  want
+	}{
+		{"default", sound.DefaultTone, want{sound.Tone{Freq: 880, Length: 200 * time.Millisecond, Count: 3}, nil}},
+		{"no count", "440Hz:1s", want{sound.Tone{Freq: 440, Length: time.Second, Count: 1}, nil}},
+		{"empty", "", want{sound.Tone{}, sound.ErrBadTone}},
+		{"no hz", "440:1s", want{sound.Tone{}, sound.ErrBadTone}},
+		{"bad length", "440hz:long", want{sound.Tone{}, sound.ErrBadTone}},
+		{"zero count", "440hz:1s x0", want{sound.Tone{}, sound.ErrBadTone}},
+		{"bad count", "440hz:1s 3", want{sound.Tone{}, sound.ErrBadTone}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tone, err := sound.ParseTone(tt.given)
+			if diff := cmp.Diff(err, tt.want.err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("parseTone error: given(-), want(+)\n%s\n", diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tone, tt.want.tone); diff != "" {
+				t.Errorf("parseTone: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestToneLength(t *testing.T) {
+	// 3 beeps and 2 silences of 200ms.
+	if diff := cmp.Diff(sound.ToneSamples("880hz:200ms x3"), 44100); diff != "" {
+		t.Errorf("tone samples: given(-), want(+)\n%s\n", diff)
+	}
+}
@@ -1,6 +1,8 @@
 package testutil
 
 import (
+	"context"
+
 	"github.com/komem3/goalarm/internal/sound"
 )
 
@@ -8,9 +10,14 @@ type MockAlarm struct{}
 
 var _ sound.Player = (*MockAlarm)(nil)
 
-func NewMockAlarm(_ string) (sound.Player, error) {
+func NewMockAlarm(_ sound.Config) (sound.Player, error) {
 	return &MockAlarm{}, nil
 }
 
-func (m *MockAlarm) Play()     {}
-func (m *MockAlarm) PlayWait() {}
+func (m *MockAlarm) Play()                       {}
+func (m *MockAlarm) PlayWait()                   {}
+func (m *MockAlarm) PlayContext(context.Context) {}
+
+func NewMockAnnouncement(_ sound.Config, _ string, bell sound.Player) sound.Player {
+	return bell
+}
@@ -0,0 +1,57 @@
+package timeserver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorCode is the kind of error result.
+type ErrorCode string
+
+const (
+	UnknownCommandCode  ErrorCode = "unknown_command"
+	InvalidArgumentCode ErrorCode = "invalid_argument"
+	InvalidStateCode    ErrorCode = "invalid_state"
+)
+
+var (
+	ErrInvalidArgument = errors.New("not support argument")
+	ErrInvalidState    = errors.New("not available in current status")
+)
+
+// errorCode returns the code of err. It is empty when err is not command error.
+func errorCode(err error) ErrorCode {
+	switch {
+	case errors.Is(err, ErrUnknownCommand):
+		return UnknownCommandCode
+	case errors.Is(err, ErrInvalidArgument):
+		return InvalidArgumentCode
+	case errors.Is(err, ErrInvalidState):
+		return InvalidStateCode
+	}
+	return ""
+}
+
+// parseCommand parses a line of input. Commands take no argument.
+func parseCommand(line string) (Command, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("'%s' is %w", line, ErrUnknownCommand)
+	}
+	cmd := Command(fields[0])
+	known := false
+	for _, c := range AllCommands() {
+		if c.Command == cmd {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return "", fmt.Errorf("'%s' is %w", line, ErrUnknownCommand)
+	}
+	if len(fields) > 1 {
+		return "", fmt.Errorf("'%s' is %w", line, ErrInvalidArgument)
+	}
+	return cmd, nil
+}
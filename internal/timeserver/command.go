@@ -3,11 +3,14 @@ package timeserver
 import "errors"
 
 const (
-	GetCommand     Command = "get"
-	StartCommand   Command = "start"
-	PauseCommand   Command = "pause"
-	StopCommand    Command = "stop"
-	RestartCommand Command = "restart"
+	GetCommand          Command = "get"
+	StartCommand        Command = "start"
+	PauseCommand        Command = "pause"
+	StopCommand         Command = "stop"
+	RestartCommand      Command = "restart"
+	NextCommand         Command = "next"
+	CapabilitiesCommand Command = "capabilities"
+	ToggleCommand       Command = "toggle"
 )
 
 var ErrUnknownCommand = errors.New("not support command")
@@ -26,5 +29,8 @@ func AllCommands() []CommandDescribe {
 		{PauseCommand, "Pause timer."},
 		{StopCommand, "Stop timer. This command stop process."},
 		{RestartCommand, "Restart timer at the first."},
+		{NextCommand, "Finish timer now. Routine goes to the next task."},
+		{CapabilitiesCommand, "Get protocol version and supported commands and statuses."},
+		{ToggleCommand, "Pause timer when running, or start timer when pause status."},
 	}
 }
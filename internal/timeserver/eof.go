@@ -0,0 +1,27 @@
+package timeserver
+
+import (
+	"errors"
+	"fmt"
+)
+
+// EOFPolicy is the behavior when input of commands is closed.
+type EOFPolicy string
+
+const (
+	// StopOnEOF stops timer with error.
+	StopOnEOF EOFPolicy = "stop"
+	// ContinueOnEOF keeps timer running until it finishes. Paused timer is resumed.
+	ContinueOnEOF EOFPolicy = "continue"
+)
+
+var ErrBadEOFPolicy = errors.New("bad eof policy")
+
+func (p EOFPolicy) Validate() error {
+	switch p {
+	case "", StopOnEOF, ContinueOnEOF:
+		return nil
+	default:
+		return fmt.Errorf("'%s' is %w. support %s or %s", p, ErrBadEOFPolicy, StopOnEOF, ContinueOnEOF)
+	}
+}
@@ -0,0 +1,63 @@
+package timeserver
+
+import "runtime/debug"
+
+// ProtocolVersion is the version of commands and results.
+// It is increased when the change breaks clients.
+const ProtocolVersion = 1
+
+// MessageType is the type of message other than result.
+type MessageType string
+
+const (
+	// HelloType is the type of message written on startup.
+	HelloType MessageType = "hello"
+	// CapabilitiesType is the type of message answering capabilities command.
+	CapabilitiesType MessageType = "capabilities"
+)
+
+// Version is the build version of goalarm.
+// It is set by -ldflags "-X github.com/komem3/goalarm/internal/timeserver.Version=v1.0.0".
+var Version = ""
+
+// Hello tells protocol and supported commands and statuses to client.
+type Hello struct {
+	Type     MessageType `json:"type"`
+	Protocol int         `json:"protocol"`
+	Version  string      `json:"version"`
+	Commands []Command   `json:"commands"`
+	Statuses []Status    `json:"statuses"`
+}
+
+// HelloHandler is the Handler which also handles hello messages.
+// Capabilities command is an error unless a subscriber implements it.
+type HelloHandler interface {
+	Handler
+	ServeHello(h Hello)
+}
+
+// NewHello returns message of typ.
+func NewHello(typ MessageType) Hello {
+	h := Hello{
+		Type:     typ,
+		Protocol: ProtocolVersion,
+		Version:  buildVersion(),
+	}
+	for _, c := range AllCommands() {
+		h.Commands = append(h.Commands, c.Command)
+	}
+	for _, s := range AllStatuses() {
+		h.Statuses = append(h.Statuses, s.Status)
+	}
+	return h
+}
+
+func buildVersion() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
@@ -0,0 +1,89 @@
+package timeserver_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/komem3/goalarm/internal/testutil"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+func TestNewHello(t *testing.T) {
+	defer func(v string) { timeserver.Version = v }(timeserver.Version)
+	timeserver.Version = "v1.2.3"
+
+	b, err := json.Marshal(timeserver.NewHello(timeserver.HelloType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"hello","protocol":1,"version":"v1.2.3",` +
+		`"commands":["get","start","pause","stop","restart","next","capabilities","toggle"],` +
+		`"statuses":["running","pause","stop","finish","warning","error"]}`
+	if diff := cmp.Diff(string(b), want); diff != "" {
+		t.Errorf("hello: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+type helloRecorder struct {
+	results []timeserver.Result
+	hellos  []timeserver.Hello
+}
+
+func (h *helloRecorder) Serve(r timeserver.Result) {
+	h.results = append(h.results, r)
+}
+
+func (h *helloRecorder) ServeHello(m timeserver.Hello) {
+	h.hellos = append(h.hellos, m)
+}
+
+func TestTimeSever_Capabilities(t *testing.T) {
+	task := timeserver.Task{Index: 1, Range: time.Second * 10, Name: "capabilities"}
+	tserver := timeserver.NewTimeServer(task)
+	recorder := new(helloRecorder)
+	var results []timeserver.Result
+	tserver.SetNow(shortTime(1, 0, 0))
+	tserver.Subscribe(recorder)
+	tserver.HandlerFunc(func(r timeserver.Result) {
+		results = append(results, r)
+	})
+	tserver.StartTimer()
+	tserver.SetNow(shortTime(1, 0, 5))
+	tserver.Listen(testutil.MockIn("capabilities\nget\n"))
+
+	if diff := cmp.Diff(len(recorder.hellos), 1); diff != "" {
+		t.Fatalf("hello length: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(recorder.hellos[0].Type, timeserver.CapabilitiesType); diff != "" {
+		t.Errorf("hello type: given(-), want(+)\n%s\n", diff)
+	}
+	for name, rs := range map[string][]timeserver.Result{"hello handler": recorder.results, "handler": results} {
+		if diff := cmp.Diff(len(rs), 2); diff != "" {
+			t.Fatalf("%s result length: given(-), want(+)\n%s\n", name, diff)
+		}
+		if diff := cmp.Diff(rs[0].Status, timeserver.RunningStatus); diff != "" {
+			t.Errorf("%s get status: given(-), want(+)\n%s\n", name, diff)
+		}
+	}
+}
+
+func TestTimeSever_CapabilitiesWithoutHello(t *testing.T) {
+	task := timeserver.Task{Index: 1, Range: time.Second * 10, Name: "capabilities"}
+	tserver := timeserver.NewTimeServer(task)
+	var results []timeserver.Result
+	tserver.HandlerFunc(func(r timeserver.Result) {
+		results = append(results, r)
+	})
+	tserver.SetStrict(true)
+	tserver.StartTimer()
+	result := tserver.Listen(testutil.MockIn("capabilities\nget\n"))
+
+	if diff := cmp.Diff(len(results), 1); diff != "" {
+		t.Fatalf("result length: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(result.Code(), timeserver.InvalidStateCode); diff != "" {
+		t.Errorf("code: given(-), want(+)\n%s\n", diff)
+	}
+}
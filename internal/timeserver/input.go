@@ -0,0 +1,47 @@
+package timeserver
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Input reads command lines from reader by one goroutine.
+// It is shared by tasks, so that every line is passed to the current task.
+type Input struct {
+	lines chan string
+	done  chan struct{}
+	// err is the read error. It is set before lines is closed.
+	err error
+}
+
+func NewInput(r io.Reader) *Input {
+	in := &Input{
+		lines: make(chan string),
+		done:  make(chan struct{}),
+	}
+	go in.read(r)
+	return in
+}
+
+func (in *Input) read(r io.Reader) {
+	buf := bufio.NewReader(r)
+	for {
+		line, err := buf.ReadString('\n')
+		if err != nil {
+			in.err = err
+			close(in.lines)
+			return
+		}
+		select {
+		case in.lines <- strings.TrimSuffix(line, "\n"):
+		case <-in.done:
+			return
+		}
+	}
+}
+
+// Close stops passing lines. The reading goroutine exits after the next line or error.
+func (in *Input) Close() {
+	close(in.done)
+}
@@ -4,14 +4,51 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 )
 
+// Result is the status of task.
+// Left is the left time formatted in seconds. LeftTime is the exact left time.
+// Deadline is the time when task finishes. It is zero when task is not running.
 type Result struct {
-	Status Status
-	Left   string
-	Error  error
-	Task   Task
+	Status   Status
+	Left     string
+	Error    error
+	Task     Task
+	LeftTime time.Duration
+	Deadline time.Time
+}
+
+// Code returns the code of error. It is empty when error is not command error.
+func (r Result) Code() ErrorCode {
+	return errorCode(r.Error)
+}
+
+// Elapsed returns elapsed time of task. It is 0 in error result.
+func (r Result) Elapsed() time.Duration {
+	if r.Status == ErrorStatus {
+		return 0
+	}
+	e := r.Task.Range - r.LeftTime
+	if e < 0 {
+		return 0
+	}
+	if e > r.Task.Range {
+		return r.Task.Range
+	}
+	return e
+}
+
+// Progress returns progress of task from 0 to 1. It is 0 in error result.
+func (r Result) Progress() float64 {
+	if r.Status == ErrorStatus {
+		return 0
+	}
+	if r.Task.Range <= 0 {
+		return 1
+	}
+	return float64(r.Elapsed()) / float64(r.Task.Range)
 }
 
 type jsonWriter struct {
@@ -39,11 +76,26 @@ func (r Result) MarshalJSON() ([]byte, error) {
 	} else {
 		jw.encode(r.Error.Error())
 	}
+	jw.writeString(",\"code\":").encode(r.Code())
 
 	jw.writeString(",\"task\":{\"index\":").encode(r.Task.Index)
 	jw.writeFormat(",\"range\":\"%s\"", r.Task.Range.Round(time.Second))
 	jw.writeFormat(",\"name\":\"%s\"}", r.Task.Name)
 
+	jw.writeString(",\"left_ms\":").encode(r.LeftTime.Milliseconds())
+	jw.writeString(",\"elapsed_ms\":").encode(r.Elapsed().Milliseconds())
+	jw.writeString(",\"deadline\":")
+	if r.Deadline.IsZero() {
+		jw.encode(nil)
+	} else {
+		jw.encode(r.Deadline.Format(time.RFC3339))
+	}
+	jw.writeString(",\"progress\":").encode(math.Round(r.Progress()*1000) / 1000)
+	jw.writeString(",\"routine_step\":").encode(r.Task.Index)
+	jw.writeString(",\"routine_total\":").encode(r.Task.Total)
+	jw.writeString(",\"loop_count\":").encode(r.Task.Loop)
+	jw.writeString(",\"drift_ms\":").encode(r.Task.Drift.Milliseconds())
+
 	jw.writeRune('}')
 	return jw.b.Bytes(), jw.err
 }
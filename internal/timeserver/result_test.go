@@ -27,7 +27,25 @@ func TestResult_MarshalJSON(t *testing.T) {
 					Name:  "normal",
 				},
 			},
-			`{"status":"running","left":"10m4s","error":"","task":{"index":1,"range":"1s","name":"normal"}}`,
+			`{"status":"running","left":"10m4s","error":"","code":"","task":{"index":1,"range":"1s","name":"normal"},"left_ms":0,"elapsed_ms":1000,"deadline":null,"progress":1,"routine_step":1,"routine_total":0,"loop_count":0,"drift_ms":0}`,
+		},
+		{
+			"routine response",
+			timeserver.Result{
+				Status:   timeserver.RunningStatus,
+				Left:     "2m0s",
+				LeftTime: time.Minute*2 - time.Millisecond*500,
+				Deadline: time.Date(2021, 1, 2, 15, 4, 5, 0, time.UTC),
+				Task: timeserver.Task{
+					Index: 2,
+					Range: time.Minute * 3,
+					Name:  "break",
+					Total: 3,
+					Loop:  1,
+					Drift: time.Second*90 + time.Millisecond*250,
+				},
+			},
+			`{"status":"running","left":"2m0s","error":"","code":"","task":{"index":2,"range":"3m0s","name":"break"},"left_ms":119500,"elapsed_ms":60500,"deadline":"2021-01-02T15:04:05Z","progress":0.336,"routine_step":2,"routine_total":3,"loop_count":1,"drift_ms":90250}`,
 		},
 		{
 			"error case",
@@ -40,7 +58,7 @@ func TestResult_MarshalJSON(t *testing.T) {
 					Name:  "second",
 				},
 			},
-			`{"status":"error","left":"","error":"err is not support command","task":{"index":2,"range":"1h0m0s","name":"second"}}`,
+			`{"status":"error","left":"","error":"err is not support command","code":"unknown_command","task":{"index":2,"range":"1h0m0s","name":"second"},"left_ms":0,"elapsed_ms":0,"deadline":null,"progress":0,"routine_step":2,"routine_total":0,"loop_count":0,"drift_ms":0}`,
 		},
 	}
 	for _, tt := range tests {
@@ -7,6 +7,7 @@ const (
 	PauseStatus   Status = "pause"
 	StopStatus    Status = "stop"
 	FinishStatus  Status = "finish"
+	WarningStatus Status = "warning"
 	ErrorStatus   Status = "error"
 )
 
@@ -21,6 +22,7 @@ func AllStatuses() []StatusDescribe {
 		{PauseStatus, "Pause timer."},
 		{StopStatus, "Stopped timer."},
 		{FinishStatus, "Finish timer."},
+		{WarningStatus, "Left time reached a warning of task."},
 		{ErrorStatus, "Error has occurred."},
 	}
 }
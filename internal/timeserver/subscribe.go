@@ -0,0 +1,189 @@
+package timeserver
+
+import "sync"
+
+// subscriberQueueSize is the max number of messages waiting for a handler.
+const subscriberQueueSize = 64
+
+// subscriber passes results to handler in its own goroutine,
+// so that a slow handler does not block the others.
+// When the queue is full, the oldest message is dropped.
+type subscriber struct {
+	handler  Handler
+	hello    HelloHandler
+	statuses map[Status]bool
+
+	mu      sync.Mutex
+	queue   []message
+	dropped int
+	closed  bool
+	wake    chan struct{}
+	done    chan struct{}
+}
+
+// message is a result or a hello message queued for handler.
+type message struct {
+	result Result
+	hello  *Hello
+}
+
+func newSubscriber(h Handler, statuses []Status) *subscriber {
+	s := &subscriber{
+		handler: h,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	s.hello, _ = h.(HelloHandler)
+	if len(statuses) > 0 {
+		s.statuses = make(map[Status]bool, len(statuses))
+		for _, status := range statuses {
+			s.statuses[status] = true
+		}
+	}
+	go s.run()
+	return s
+}
+
+func (s *subscriber) run() {
+	defer close(s.done)
+	for range s.wake {
+		for {
+			s.mu.Lock()
+			if len(s.queue) == 0 {
+				closed := s.closed
+				s.mu.Unlock()
+				if closed {
+					return
+				}
+				break
+			}
+			m := s.queue[0]
+			s.queue = s.queue[1:]
+			s.mu.Unlock()
+			if m.hello != nil {
+				s.hello.ServeHello(*m.hello)
+			} else {
+				s.handler.Serve(m.result)
+			}
+		}
+	}
+}
+
+func (s *subscriber) push(r Result) {
+	if s.statuses != nil && !s.statuses[r.Status] {
+		return
+	}
+	s.enqueue(message{result: r})
+}
+
+// pushHello queues h when handler handles hello messages.
+func (s *subscriber) pushHello(h Hello) {
+	if s.hello == nil {
+		return
+	}
+	s.enqueue(message{hello: &h})
+}
+
+func (s *subscriber) enqueue(m message) {
+	s.mu.Lock()
+	if !s.closed {
+		if len(s.queue) >= subscriberQueueSize {
+			s.queue = s.queue[1:]
+			s.dropped++
+		}
+		s.queue = append(s.queue, m)
+	}
+	s.mu.Unlock()
+	s.notify()
+}
+
+// close stops subscriber. Queued results are passed unless discard is true.
+func (s *subscriber) close(discard bool) {
+	s.mu.Lock()
+	s.closed = true
+	if discard {
+		s.queue = nil
+	}
+	s.mu.Unlock()
+	s.notify()
+}
+
+func (s *subscriber) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Subscribe passes results of statuses to h. No statuses passes all results.
+// Each handler is called in order of results, independently of other handlers.
+// Results are passed until unsubscribe is called or the task ends.
+// Results not handled yet are discarded by unsubscribe.
+// A slow handler drops the oldest results over subscriberQueueSize. See Dropped.
+// HelloHandler also gets hello messages in order of results.
+func (t *timeServer) Subscribe(h Handler, statuses ...Status) (unsubscribe func()) {
+	s := newSubscriber(h, statuses)
+	t.subsMu.Lock()
+	t.subs = append(t.subs, s)
+	t.subsMu.Unlock()
+	return func() {
+		t.subsMu.Lock()
+		for i, sub := range t.subs {
+			if sub == s {
+				t.subs = append(t.subs[:i:i], t.subs[i+1:]...)
+				break
+			}
+		}
+		t.subsMu.Unlock()
+		s.close(true)
+	}
+}
+
+// serve passes result to subscribers.
+func (t *timeServer) serve(r Result) {
+	t.subsMu.Lock()
+	defer t.subsMu.Unlock()
+	for _, s := range t.subs {
+		s.push(r)
+	}
+}
+
+// serveHello passes h to subscribers handling hello messages.
+func (t *timeServer) serveHello(h Hello) {
+	t.subsMu.Lock()
+	defer t.subsMu.Unlock()
+	for _, s := range t.subs {
+		s.pushHello(h)
+	}
+}
+
+// handlesHello reports whether any subscriber handles hello messages.
+func (t *timeServer) handlesHello() bool {
+	t.subsMu.Lock()
+	defer t.subsMu.Unlock()
+	for _, s := range t.subs {
+		if s.hello != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// closeSubscribers waits for subscribers to handle all results.
+func (t *timeServer) closeSubscribers() {
+	t.subsMu.Lock()
+	subs := t.subs
+	t.subs = nil
+	t.subsMu.Unlock()
+	for _, s := range subs {
+		s.close(false)
+		<-s.done
+		t.dropped += s.dropped
+	}
+}
+
+// Dropped returns the number of results and messages dropped by full queues of subscribers.
+// It is counted when the task ends, and unsubscribed handlers are not counted.
+func (t *timeServer) Dropped() int {
+	return t.dropped
+}
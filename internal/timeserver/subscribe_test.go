@@ -0,0 +1,98 @@
+package timeserver_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/komem3/goalarm/internal/testutil"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+func TestTimeSever_Subscribe(t *testing.T) {
+	tserver := timeserver.NewTimeServer(timeserver.Task{Range: time.Minute, Name: "subscribe"})
+
+	var all, pause, blocked []timeserver.Status
+	release := make(chan struct{})
+	tserver.Subscribe(timeserver.HandlerFunc(func(r timeserver.Result) {
+		all = append(all, r.Status)
+		if r.Status == timeserver.StopStatus {
+			close(release)
+		}
+	}))
+	tserver.Subscribe(timeserver.HandlerFunc(func(r timeserver.Result) {
+		pause = append(pause, r.Status)
+	}), timeserver.PauseStatus)
+	// slow handler waits for the other handler.
+	tserver.Subscribe(timeserver.HandlerFunc(func(r timeserver.Result) {
+		select {
+		case <-release:
+		case <-time.After(time.Second):
+			t.Error("slow handler blocks other handlers")
+		}
+		blocked = append(blocked, r.Status)
+	}))
+	tserver.StartTimer()
+	tserver.Listen(testutil.MockIn("get\npause\nstop\n"))
+
+	want := []timeserver.Status{timeserver.RunningStatus, timeserver.PauseStatus, timeserver.StopStatus}
+	if diff := cmp.Diff(all, want); diff != "" {
+		t.Errorf("all statuses: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(blocked, want); diff != "" {
+		t.Errorf("slow handler statuses: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(pause, []timeserver.Status{timeserver.PauseStatus}); diff != "" {
+		t.Errorf("filtered statuses: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestTimeSever_Unsubscribe(t *testing.T) {
+	tserver := timeserver.NewTimeServer(timeserver.Task{Range: time.Minute, Name: "unsubscribe"})
+
+	var statuses []timeserver.Status
+	var unsubscribe func()
+	unsubscribe = tserver.Subscribe(timeserver.HandlerFunc(func(r timeserver.Result) {
+		statuses = append(statuses, r.Status)
+		unsubscribe()
+	}))
+	var replaced, handled int
+	tserver.HandlerFunc(func(timeserver.Result) { replaced++ })
+	tserver.HandlerFunc(func(timeserver.Result) { handled++ })
+	tserver.StartTimer()
+	tserver.Listen(testutil.MockIn("get\npause\nstop\n"))
+
+	if diff := cmp.Diff(statuses, []timeserver.Status{timeserver.RunningStatus}); diff != "" {
+		t.Errorf("statuses: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]int{replaced, handled}, []int{0, 3}); diff != "" {
+		t.Errorf("handled count of HandlerFunc: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestTimeSever_SubscribeOverflow(t *testing.T) {
+	tserver := timeserver.NewTimeServer(timeserver.Task{Range: time.Minute, Name: "overflow"})
+
+	release := make(chan struct{})
+	tserver.Subscribe(timeserver.HandlerFunc(func(timeserver.Result) {
+		close(release)
+	}), timeserver.StopStatus)
+	var slow []timeserver.Status
+	tserver.Subscribe(timeserver.HandlerFunc(func(r timeserver.Result) {
+		<-release
+		slow = append(slow, r.Status)
+	}))
+	tserver.StartTimer()
+	tserver.Listen(testutil.MockIn(strings.Repeat("get\n", 100) + "stop\n"))
+
+	if diff := cmp.Diff(len(slow)+tserver.Dropped(), 101); diff != "" {
+		t.Errorf("handled and dropped: given(-), want(+)\n%s\n", diff)
+	}
+	if len(slow) > 65 {
+		t.Errorf("handled %d results over the queue size", len(slow))
+	}
+	if diff := cmp.Diff(slow[len(slow)-1], timeserver.StopStatus); diff != "" {
+		t.Errorf("last status: given(-), want(+)\n%s\n", diff)
+	}
+}
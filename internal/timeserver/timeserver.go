@@ -1,10 +1,12 @@
 package timeserver
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"sort"
+	"sync"
 	"time"
 )
 
@@ -12,6 +14,14 @@ type Task struct {
 	Index int
 	Range time.Duration
 	Name  string
+	// Warnings are left times when warning is notified.
+	Warnings []time.Duration
+	// Total is the number of tasks in routine. It is 0 in alarm mode.
+	Total int
+	// Loop is the count of finished loops of routine or alarm.
+	Loop int
+	// Drift is how late the task started against the schedule of routine. Negative is early.
+	Drift time.Duration
 }
 
 type timeServer struct {
@@ -20,156 +30,330 @@ type timeServer struct {
 	start   time.Time
 	task    Task
 	now     func() time.Time
-	handler Handler
-	reader  io.Reader
+	input   *Input
+	tick    time.Duration
+	strict  bool
+	onEOF   EOFPolicy
+	// commands are executed in addition to commands from reader.
+	commands <-chan Command
+
+	stateMu   sync.Mutex
+	status    Status
+	pauseLeft time.Duration
+
+	subsMu  sync.Mutex
+	subs    []*subscriber
+	dropped int
+	// unsetHandler unsubscribes the handler of HandlerFunc.
+	unsetHandler func()
+
+	warnMu   sync.Mutex
+	warner   *time.Timer
+	warnings []time.Duration
+	warning  time.Duration
 }
 
 type Handler interface {
 	Serve(r Result)
 }
 
-type handlerFunc func(r Result)
+// HandlerFunc is the function used as Handler.
+type HandlerFunc func(r Result)
 
-func (h handlerFunc) Serve(r Result) {
+func (h HandlerFunc) Serve(r Result) {
 	h(r)
 }
 
 func NewTimeServer(task Task) *timeServer {
+	warnings := make([]time.Duration, len(task.Warnings))
+	copy(warnings, task.Warnings)
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i] > warnings[j]
+	})
 	tserver := &timeServer{
-		task:    task,
-		now:     time.Now,
-		running: false,
+		task:     task,
+		now:      time.Now,
+		running:  false,
+		warnings: warnings,
 	}
 	return tserver
 }
 
 func (t *timeServer) StartTimer() {
+	t.status = RunningStatus
 	t.start = t.now()
 	t.ticker = time.NewTimer(t.task.Range)
+	t.warner = time.NewTimer(0)
+	t.resetWarning(t.task.Range)
+}
+
+// resetWarning schedules the first warning shorter than left.
+func (t *timeServer) resetWarning(left time.Duration) {
+	t.warnMu.Lock()
+	defer t.warnMu.Unlock()
+	t.stopWarning()
+	for _, w := range t.warnings {
+		if w < left {
+			t.warning = w
+			t.warner.Reset(left - w)
+			return
+		}
+	}
+}
+
+func (t *timeServer) stopWarning() {
+	if !t.warner.Stop() {
+		select {
+		case <-t.warner.C:
+		default:
+		}
+	}
 }
 
+// HandlerFunc replaces the handler set by the previous call. It does not affect Subscribe.
 func (t *timeServer) HandlerFunc(f func(r Result)) {
-	t.handler = handlerFunc(f)
+	if t.unsetHandler != nil {
+		t.unsetHandler()
+	}
+	t.unsetHandler = t.Subscribe(HandlerFunc(f))
 }
 
+// Listen serves commands read from in until the task ends.
 func (t *timeServer) Listen(in io.Reader) (result Result) {
+	input := NewInput(in)
+	defer input.Close()
+	return t.ListenInput(input)
+}
+
+// ListenInput serves commands of in until the task ends.
+// Lines not received remain in the input for the next task.
+func (t *timeServer) ListenInput(in *Input) (result Result) {
+	return t.ListenContext(context.Background(), in)
+}
+
+// ListenContext is ListenInput which stops the task when ctx is done.
+// The last result has stop status and the error of ctx.
+// It returns after subscribers handle all results.
+func (t *timeServer) ListenContext(ctx context.Context, in *Input) (result Result) {
 	t.running = true
-	t.reader = in
+	t.input = in
+	defer t.closeSubscribers()
 	defer t.ticker.Stop()
-	return t.finishRace(t.readCommand, t.finish)
+	defer t.warner.Stop()
+	return t.loop(ctx)
 }
 
-func (t *timeServer) readCommand(ctx context.Context) (result Result) {
-	status := RunningStatus
-	var pauseLeft time.Duration
-	buf := bufio.NewReader(t.reader)
+// SetTick sets interval of serving current status. 0 disables it.
+func (t *timeServer) SetTick(d time.Duration) {
+	t.tick = d
+}
 
-	for t.running {
-		line, err := buf.ReadString('\n')
-		if err != nil {
-			result = Result{
-				Status: ErrorStatus,
-				Error:  err,
-				Task:   t.task,
-			}
-			t.handler.Serve(result)
-			t.running = false
-		}
+// SetStrict sets strict mode. In strict mode, command errors stop the server.
+func (t *timeServer) SetStrict(strict bool) {
+	t.strict = strict
+}
 
-		// when finished while reading input
-		if !t.running {
-			break
-		}
+// SetEOFPolicy sets behavior when input is closed. Default is StopOnEOF.
+func (t *timeServer) SetEOFPolicy(p EOFPolicy) {
+	t.onEOF = p
+}
+
+// SetCommands sets source of commands other than reader. e.g. signals
+func (t *timeServer) SetCommands(c <-chan Command) {
+	t.commands = c
+}
+
+// result returns result with left time. stateMu must be locked.
+func (t *timeServer) result(status Status, left time.Duration) Result {
+	r := Result{
+		Status:   status,
+		Left:     fmt.Sprintf("%s", left.Round(time.Second)),
+		LeftTime: left,
+		Task:     t.task,
+	}
+	if status == RunningStatus || status == WarningStatus {
+		r.Deadline = t.start.Add(t.task.Range)
+	}
+	return r
+}
+
+// left returns left time of task. stateMu must be locked.
+func (t *timeServer) left() time.Duration {
+	if t.status == PauseStatus {
+		return t.pauseLeft
+	}
+	return t.task.Range - t.now().Sub(t.start)
+}
+
+// line handles a line of input.
+func (t *timeServer) line(line string) (result Result) {
+	cmd, err := parseCommand(line)
+	if err != nil {
+		t.stateMu.Lock()
+		result = t.commandError(err)
+		t.stateMu.Unlock()
+		t.serve(result)
+		return result
+	}
+	return t.exec(cmd)
+}
+
+// commandError returns the error result of command. stateMu must be locked.
+// In strict mode, it stops the server.
+func (t *timeServer) commandError(err error) (result Result) {
+	result = t.result(ErrorStatus, t.left())
+	result.Error = err
+	if t.strict {
+		t.running = false
+	}
+	return result
+}
+
+// exec executes cmd and serves the result.
+// Capabilities command serves the hello message instead.
+func (t *timeServer) exec(cmd Command) (result Result) {
+	result, hello := t.execute(cmd)
+	if hello != nil {
+		t.serveHello(*hello)
+	} else {
+		t.serve(result)
+	}
+	return result
+}
 
-		var left time.Duration
-		if status == PauseStatus {
-			left = pauseLeft
-		} else {
-			left = t.task.Range - t.now().Sub(t.start)
+// execute executes cmd and returns the result, and the hello message answering capabilities command.
+func (t *timeServer) execute(cmd Command) (result Result, hello *Hello) {
+	t.stateMu.Lock()
+	defer t.stateMu.Unlock()
+	left := t.left()
+
+	if cmd == ToggleCommand {
+		cmd = PauseCommand
+		if t.status == PauseStatus {
+			cmd = StartCommand
+		}
+	}
+	switch cmd {
+	case GetCommand:
+		result = t.result(t.status, left)
+	case StartCommand:
+		t.status = RunningStatus
+		t.start = t.now().Add(left - t.task.Range)
+		t.ticker.Stop()
+		t.ticker.Reset(left)
+		t.resetWarning(left)
+		result = t.result(t.status, left)
+	case PauseCommand:
+		t.status = PauseStatus
+		t.ticker.Stop()
+		t.warnMu.Lock()
+		t.stopWarning()
+		t.warnMu.Unlock()
+		t.pauseLeft = left
+		result = t.result(t.status, left)
+	case StopCommand:
+		t.ticker.Stop()
+		result = t.result(StopStatus, left)
+		t.running = false
+	case RestartCommand:
+		t.status = RunningStatus
+		t.start = t.now()
+		t.ticker.Stop()
+		t.ticker.Reset(t.task.Range)
+		t.resetWarning(t.task.Range)
+		result = t.result(t.status, t.task.Range)
+	case NextCommand:
+		t.ticker.Stop()
+		result = t.result(FinishStatus, left)
+		t.running = false
+	case CapabilitiesCommand:
+		if !t.handlesHello() {
+			return t.commandError(fmt.Errorf("'%s' is %w: output has no handshake message", cmd, ErrInvalidState)), nil
 		}
-		leftSec := fmt.Sprintf("%s", left.Round(time.Second))
+		result = t.result(t.status, left)
+		h := NewHello(CapabilitiesType)
+		hello = &h
+	}
+	return result, hello
+}
 
-		switch Command(line[:len(line)-1]) {
-		case GetCommand:
-			result = Result{
-				Left:   leftSec,
-				Status: status,
-				Task:   t.task,
-			}
-		case StartCommand:
-			status = RunningStatus
-			t.start = t.now()
-			t.ticker.Stop()
-			t.ticker.Reset(left)
-			result = Result{
-				Left:   leftSec,
-				Status: status,
-				Task:   t.task,
+// loop serves commands, warnings and ticks until the task ends.
+func (t *timeServer) loop(ctx context.Context) (result Result) {
+	var tick <-chan time.Time
+	if t.tick > 0 {
+		ticker := time.NewTicker(t.tick)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	lines := t.input.lines
+	for {
+		select {
+		case <-ctx.Done():
+			t.running = false
+			t.stateMu.Lock()
+			result = t.result(StopStatus, t.left())
+			t.stateMu.Unlock()
+			result.Error = ctx.Err()
+			t.serve(result)
+			return result
+		case line, ok := <-lines:
+			if !ok {
+				err := t.input.err
+				if errors.Is(err, io.EOF) && t.onEOF == ContinueOnEOF {
+					// wait for the finish without input.
+					// Paused timer is resumed, because no command can start it anymore.
+					lines = nil
+					t.stateMu.Lock()
+					paused := t.status == PauseStatus
+					t.stateMu.Unlock()
+					if paused {
+						result = t.exec(StartCommand)
+					}
+					continue
+				}
+				result = Result{
+					Status: ErrorStatus,
+					Error:  err,
+					Task:   t.task,
+				}
+				t.serve(result)
+				return result
 			}
-		case PauseCommand:
-			status = PauseStatus
-			t.ticker.Stop()
-			pauseLeft = left
-			result = Result{
-				Left:   leftSec,
-				Status: status,
-				Task:   t.task,
+			result = t.line(line)
+			if !t.running {
+				return result
 			}
-		case StopCommand:
-			t.ticker.Stop()
-			result = Result{
-				Left:   leftSec,
-				Status: StopStatus,
-				Task:   t.task,
+		case cmd := <-t.commands:
+			result = t.exec(cmd)
+			if !t.running {
+				return result
 			}
+		case <-tick:
+			t.stateMu.Lock()
+			r := t.result(t.status, t.left())
+			t.stateMu.Unlock()
+			t.serve(r)
+		case <-t.warner.C:
+			t.warn()
+		case <-t.ticker.C:
 			t.running = false
-		case RestartCommand:
-			status = RunningStatus
-			t.start = t.now()
-			t.ticker.Stop()
-			t.ticker.Reset(t.task.Range)
-			result = Result{
-				Left:   fmt.Sprintf("%s", t.task.Range.Round(time.Second)),
-				Status: status,
-				Task:   t.task,
-			}
-		default:
-			err = fmt.Errorf("'%s' is %w", line[:len(line)-1], ErrUnknownCommand)
 			result = Result{
-				Status: ErrorStatus,
-				Error:  err,
+				Status: FinishStatus,
 				Task:   t.task,
 			}
-			t.running = false
-		}
-		t.handler.Serve(result)
-	}
-	return result
-}
-
-func (t *timeServer) finish(ctx context.Context) (result Result) {
-	select {
-	case <-ctx.Done():
-	case <-t.ticker.C:
-		t.running = false
-		result = Result{
-			Status: FinishStatus,
-			Task:   t.task,
+			t.serve(result)
+			return result
 		}
-		t.handler.Serve(result)
 	}
-	return result
 }
 
-func (t *timeServer) finishRace(fs ...func(ctx context.Context) Result) Result {
-	results := make(chan Result, len(fs))
-	ctx, cancel := context.WithCancel(context.Background())
-	for _, f := range fs {
-		go func(f func(context.Context) Result) {
-			results <- f(ctx)
-		}(f)
-	}
-	r := <-results
-	cancel()
-	return r
+func (t *timeServer) warn() {
+	t.warnMu.Lock()
+	w := t.warning
+	t.warnMu.Unlock()
+	t.stateMu.Lock()
+	r := t.result(WarningStatus, w)
+	t.stateMu.Unlock()
+	t.serve(r)
+	t.resetWarning(w)
 }
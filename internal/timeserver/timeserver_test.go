@@ -1,6 +1,7 @@
 package timeserver_test
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"testing"
@@ -150,6 +151,26 @@ var oneCommandTestCase = testcases{
 			},
 		},
 	},
+	{
+		"next command",
+		given{
+			task: timeserver.Task{
+				Index: 1,
+				Range: time.Minute,
+				Name:  "next",
+			},
+			commandTime: shortTime(1, 0, 20),
+			command:     string(timeserver.NextCommand),
+		},
+		want{
+			results: []timeserver.Result{
+				{
+					Status: timeserver.FinishStatus,
+					Left:   "40s",
+				},
+			},
+		},
+	},
 	{
 		"unknown command",
 		given{
@@ -167,6 +188,34 @@ var oneCommandTestCase = testcases{
 					Status: timeserver.ErrorStatus,
 					Error:  timeserver.ErrUnknownCommand,
 				},
+				{
+					Status: timeserver.ErrorStatus,
+					Error:  io.EOF,
+				},
+			},
+		},
+	},
+	{
+		"invalid argument",
+		given{
+			task: timeserver.Task{
+				Index: 1,
+				Range: time.Second * 10,
+				Name:  "argument",
+			},
+			commandTime: shortTime(1, 0, 5),
+			command:     "get now",
+		},
+		want{
+			results: []timeserver.Result{
+				{
+					Status: timeserver.ErrorStatus,
+					Error:  timeserver.ErrInvalidArgument,
+				},
+				{
+					Status: timeserver.ErrorStatus,
+					Error:  io.EOF,
+				},
 			},
 		},
 	},
@@ -663,6 +712,45 @@ var twoCommandTestCase = testcases{
 	},
 }
 
+var threeCommandTestCase = testcases{
+	{
+		"pause start get",
+		given{
+			task: timeserver.Task{
+				Index: 1,
+				Range: time.Second * 10,
+				Name:  "pause start get",
+			},
+			commandTime: shortTime(1, 0, 5),
+			command: fmt.Sprintf("%s\n%s\n%s",
+				timeserver.PauseCommand,
+				timeserver.StartCommand,
+				timeserver.GetCommand,
+			),
+		},
+		want{
+			results: []timeserver.Result{
+				{
+					Status: timeserver.PauseStatus,
+					Left:   "5s",
+				},
+				{
+					Status: timeserver.RunningStatus,
+					Left:   "5s",
+				},
+				{
+					Status: timeserver.RunningStatus,
+					Left:   "5s",
+				},
+				{
+					Status: timeserver.ErrorStatus,
+					Error:  io.EOF,
+				},
+			},
+		},
+	},
+}
+
 var finishTestCase = testcases{
 	{
 		name: "alarm finish",
@@ -698,6 +786,10 @@ func TestTimeSever_Listen(t *testing.T) {
 			"two command",
 			twoCommandTestCase,
 		},
+		{
+			"three command",
+			threeCommandTestCase,
+		},
 		{
 			"finish alarm",
 			finishTestCase,
@@ -739,7 +831,7 @@ func TestTimeSever_Listen(t *testing.T) {
 							if diff := cmp.Diff(lastResult.Error, tt.want.results[i].Error, cmpopts.EquateErrors()); diff != "" {
 								t.Errorf("latestResult.Error: given(-), want(+)\n%s\n", diff)
 							}
-							if diff := cmp.Diff(lastResult, tt.want.results[i], cmpopts.IgnoreFields(timeserver.Result{}, "Error")); diff != "" {
+							if diff := cmp.Diff(lastResult, tt.want.results[i], cmpopts.IgnoreFields(timeserver.Result{}, "Error", "LeftTime", "Deadline")); diff != "" {
 								t.Errorf("result: given(-), want(+)\n%s\n", diff)
 							}
 						}
@@ -750,6 +842,324 @@ func TestTimeSever_Listen(t *testing.T) {
 	}
 }
 
+// ignoreTime ignores exact times of results depending on real clock.
+var ignoreTime = cmpopts.IgnoreFields(timeserver.Result{}, "LeftTime", "Deadline")
+
 func shortTime(h, min, sec int) time.Time {
 	return time.Date(2010, 1, 1, h, min, sec, 0, time.Local)
 }
+
+func TestTimeSever_Warning(t *testing.T) {
+	task := timeserver.Task{
+		Index:    1,
+		Range:    time.Millisecond * 150,
+		Name:     "warning",
+		Warnings: []time.Duration{time.Millisecond * 50, time.Second, time.Millisecond * 100},
+	}
+	tserver := timeserver.NewTimeServer(task)
+	var results []timeserver.Result
+	tserver.HandlerFunc(func(r timeserver.Result) {
+		results = append(results, r)
+	})
+	tserver.StartTimer()
+
+	r, w := io.Pipe()
+	defer w.Close()
+	lastResult := tserver.Listen(r)
+
+	want := []timeserver.Result{
+		{Status: timeserver.WarningStatus, Left: "0s", Task: task},
+		{Status: timeserver.WarningStatus, Left: "0s", Task: task},
+		{Status: timeserver.FinishStatus, Task: task},
+	}
+	if diff := cmp.Diff(results, want, ignoreTime); diff != "" {
+		t.Errorf("results: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(lastResult, want[len(want)-1], ignoreTime); diff != "" {
+		t.Errorf("last result: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestTimeSever_Tick(t *testing.T) {
+	task := timeserver.Task{
+		Index: 1,
+		Range: time.Millisecond * 120,
+		Name:  "tick",
+	}
+	tserver := timeserver.NewTimeServer(task)
+	var results []timeserver.Result
+	tserver.HandlerFunc(func(r timeserver.Result) {
+		results = append(results, r)
+	})
+	tserver.SetTick(time.Millisecond * 50)
+	tserver.StartTimer()
+
+	r, w := io.Pipe()
+	defer w.Close()
+	tserver.Listen(r)
+
+	want := []timeserver.Result{
+		{Status: timeserver.RunningStatus, Left: "0s", Task: task},
+		{Status: timeserver.RunningStatus, Left: "0s", Task: task},
+		{Status: timeserver.FinishStatus, Task: task},
+	}
+	if diff := cmp.Diff(results, want, ignoreTime); diff != "" {
+		t.Errorf("results: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestTimeSever_LeftTime(t *testing.T) {
+	task := timeserver.Task{
+		Index: 1,
+		Range: time.Minute,
+		Name:  "left",
+	}
+	tests := []struct {
+		name    string
+		command timeserver.Command
+		want    timeserver.Result
+	}{
+		{
+			"get command",
+			timeserver.GetCommand,
+			timeserver.Result{
+				Status:   timeserver.RunningStatus,
+				Left:     "50s",
+				LeftTime: time.Second*49 + time.Millisecond*600,
+				Deadline: shortTime(1, 1, 0),
+				Task:     task,
+			},
+		},
+		{
+			"pause command",
+			timeserver.PauseCommand,
+			timeserver.Result{
+				Status:   timeserver.PauseStatus,
+				Left:     "50s",
+				LeftTime: time.Second*49 + time.Millisecond*600,
+				Task:     task,
+			},
+		},
+		{
+			"restart command",
+			timeserver.RestartCommand,
+			timeserver.Result{
+				Status:   timeserver.RunningStatus,
+				Left:     "1m0s",
+				LeftTime: time.Minute,
+				Deadline: shortTime(1, 1, 10).Add(time.Millisecond * 400),
+				Task:     task,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tserver := timeserver.NewTimeServer(task)
+			var results []timeserver.Result
+			tserver.SetNow(shortTime(1, 0, 0))
+			tserver.HandlerFunc(func(r timeserver.Result) {
+				results = append(results, r)
+			})
+			tserver.StartTimer()
+			tserver.SetNow(shortTime(1, 0, 10).Add(time.Millisecond * 400))
+			tserver.Listen(testutil.MockIn(fmt.Sprintf("%s\n", tt.command)))
+			if len(results) == 0 {
+				t.Fatal("no result")
+			}
+			if diff := cmp.Diff(results[0], tt.want); diff != "" {
+				t.Errorf("result: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestTimeSever_Strict(t *testing.T) {
+	task := timeserver.Task{Index: 1, Range: time.Second * 10, Name: "strict"}
+	tests := []struct {
+		name     string
+		strict   bool
+		want     []timeserver.ErrorCode
+		wantLast error
+	}{
+		{"continue", false, []timeserver.ErrorCode{timeserver.UnknownCommandCode, "", ""}, nil},
+		{"strict", true, []timeserver.ErrorCode{timeserver.UnknownCommandCode}, timeserver.ErrUnknownCommand},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tserver := timeserver.NewTimeServer(task)
+			var codes []timeserver.ErrorCode
+			tserver.HandlerFunc(func(r timeserver.Result) {
+				codes = append(codes, r.Code())
+			})
+			tserver.SetStrict(tt.strict)
+			tserver.StartTimer()
+			last := tserver.Listen(testutil.MockIn("unknown\nget\nstop\n"))
+			if diff := cmp.Diff(codes, tt.want); diff != "" {
+				t.Errorf("codes: given(-), want(+)\n%s\n", diff)
+			}
+			if diff := cmp.Diff(last.Error, tt.wantLast, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("last result error: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestTimeSever_ContinueOnEOFPaused(t *testing.T) {
+	task := timeserver.Task{Index: 1, Range: time.Millisecond * 50, Name: "eof"}
+	tserver := timeserver.NewTimeServer(task)
+	var statuses []timeserver.Status
+	tserver.HandlerFunc(func(r timeserver.Result) {
+		statuses = append(statuses, r.Status)
+	})
+	tserver.SetEOFPolicy(timeserver.ContinueOnEOF)
+	tserver.StartTimer()
+	lastResult := tserver.Listen(testutil.MockIn("pause\n"))
+
+	want := []timeserver.Status{timeserver.PauseStatus, timeserver.RunningStatus, timeserver.FinishStatus}
+	if diff := cmp.Diff(statuses, want); diff != "" {
+		t.Errorf("statuses: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(lastResult.Status, timeserver.FinishStatus); diff != "" {
+		t.Errorf("last status: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestTimeSever_StrictRedundantCommand(t *testing.T) {
+	task := timeserver.Task{Index: 1, Range: time.Second * 10, Name: "strict"}
+	tserver := timeserver.NewTimeServer(task)
+	var statuses []timeserver.Status
+	tserver.HandlerFunc(func(r timeserver.Result) {
+		statuses = append(statuses, r.Status)
+	})
+	tserver.SetStrict(true)
+	tserver.StartTimer()
+	tserver.Listen(testutil.MockIn("start\npause\npause\nstop\n"))
+	want := []timeserver.Status{
+		timeserver.RunningStatus, timeserver.PauseStatus, timeserver.PauseStatus, timeserver.StopStatus,
+	}
+	if diff := cmp.Diff(statuses, want); diff != "" {
+		t.Errorf("statuses: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestTimeSever_ContinueOnEOF(t *testing.T) {
+	task := timeserver.Task{Index: 1, Range: time.Millisecond * 50, Name: "eof"}
+	tserver := timeserver.NewTimeServer(task)
+	var results []timeserver.Result
+	tserver.HandlerFunc(func(r timeserver.Result) {
+		results = append(results, r)
+	})
+	tserver.SetEOFPolicy(timeserver.ContinueOnEOF)
+	tserver.StartTimer()
+	lastResult := tserver.Listen(testutil.MockIn(""))
+
+	want := []timeserver.Result{
+		{Status: timeserver.FinishStatus, Task: task},
+	}
+	if diff := cmp.Diff(results, want, ignoreTime); diff != "" {
+		t.Errorf("results: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(lastResult, want[0], ignoreTime); diff != "" {
+		t.Errorf("last result: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestEOFPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		given timeserver.EOFPolicy
+		want  error
+	}{
+		{"", nil},
+		{timeserver.StopOnEOF, nil},
+		{timeserver.ContinueOnEOF, nil},
+		{"ignore", timeserver.ErrBadEOFPolicy},
+	}
+	for _, tt := range tests {
+		if diff := cmp.Diff(tt.given.Validate(), tt.want, cmpopts.EquateErrors()); diff != "" {
+			t.Errorf("validate %s: given(-), want(+)\n%s\n", tt.given, diff)
+		}
+	}
+}
+
+func TestTimeSever_Commands(t *testing.T) {
+	task := timeserver.Task{Index: 1, Range: time.Minute, Name: "commands"}
+	tserver := timeserver.NewTimeServer(task)
+	var results []timeserver.Result
+	tserver.SetNow(shortTime(1, 0, 0))
+	tserver.HandlerFunc(func(r timeserver.Result) {
+		results = append(results, r)
+	})
+	commands := make(chan timeserver.Command, 4)
+	commands <- timeserver.ToggleCommand
+	commands <- timeserver.ToggleCommand
+	commands <- timeserver.GetCommand
+	commands <- timeserver.StopCommand
+	tserver.SetCommands(commands)
+	tserver.StartTimer()
+
+	r, w := io.Pipe()
+	defer w.Close()
+	lastResult := tserver.Listen(r)
+
+	want := []timeserver.Result{
+		{Status: timeserver.PauseStatus, Left: "1m0s", Task: task},
+		{Status: timeserver.RunningStatus, Left: "1m0s", Task: task},
+		{Status: timeserver.RunningStatus, Left: "1m0s", Task: task},
+		{Status: timeserver.StopStatus, Left: "1m0s", Task: task},
+	}
+	if diff := cmp.Diff(results, want, ignoreTime); diff != "" {
+		t.Errorf("results: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(lastResult, want[len(want)-1], ignoreTime); diff != "" {
+		t.Errorf("last result: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestInput(t *testing.T) {
+	in := timeserver.NewInput(testutil.MockIn("next\nget\n"))
+	defer in.Close()
+	var names []string
+	for _, name := range []string{"first", "second", "third"} {
+		tserver := timeserver.NewTimeServer(timeserver.Task{Range: time.Minute, Name: name})
+		tserver.HandlerFunc(func(timeserver.Result) {})
+		tserver.StartTimer()
+		r := tserver.ListenInput(in)
+		names = append(names, fmt.Sprintf("%s %s", r.Task.Name, r.Status))
+	}
+	want := []string{"first finish", "second error", "third error"}
+	if diff := cmp.Diff(names, want); diff != "" {
+		t.Errorf("results: given(-), want(+)\n%s\n", diff)
+	}
+}
+
+func TestTimeSever_ListenContext(t *testing.T) {
+	task := timeserver.Task{Index: 1, Range: time.Minute, Name: "context"}
+	tserver := timeserver.NewTimeServer(task)
+	var results []timeserver.Result
+	tserver.HandlerFunc(func(r timeserver.Result) {
+		results = append(results, r)
+	})
+	tserver.StartTimer()
+
+	r, w := io.Pipe()
+	defer w.Close()
+	in := timeserver.NewInput(r)
+	defer in.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	lastResult := tserver.ListenContext(ctx, in)
+
+	want := []timeserver.Result{
+		{Status: timeserver.StopStatus, Left: "1m0s", Error: context.DeadlineExceeded, Task: task},
+	}
+	if diff := cmp.Diff(results, want, ignoreTime, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("results: given(-), want(+)\n%s\n", diff)
+	}
+	if diff := cmp.Diff(lastResult, want[0], ignoreTime, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("last result: given(-), want(+)\n%s\n", diff)
+	}
+}
@@ -0,0 +1,22 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+type State = state
+
+func NewState(status timeserver.Status, left time.Duration, task timeserver.Task) State {
+	return state{status: status, left: left, task: task}
+}
+
+func Render(s State, tasks []timeserver.Task) []string {
+	return render(s, tasks)
+}
+
+func RunOn(screen tcell.Screen, tasks []timeserver.Task, serve ServeFunc) error {
+	return newUI(screen, tasks).run(serve)
+}
@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+const (
+	barWidth  = 40
+	upcomings = 3
+)
+
+var bigDigits = map[rune][5]string{
+	'0': {"███", "█ █", "█ █", "█ █", "███"},
+	'1': {"  █", "  █", "  █", "  █", "  █"},
+	'2': {"███", "  █", "███", "█  ", "███"},
+	'3': {"███", "  █", "███", "  █", "███"},
+	'4': {"█ █", "█ █", "███", "  █", "  █"},
+	'5': {"███", "█  ", "███", "  █", "███"},
+	'6': {"███", "█  ", "███", "█ █", "███"},
+	'7': {"███", "  █", "  █", "  █", "  █"},
+	'8': {"███", "█ █", "███", "█ █", "███"},
+	'9': {"███", "█ █", "███", "  █", "███"},
+	':': {" ", "█", " ", "█", " "},
+}
+
+const keyHelp = "space: pause/start  r: restart  n: next  q: stop"
+
+// state is the latest status of timer.
+type state struct {
+	status timeserver.Status
+	left   time.Duration
+	task   timeserver.Task
+	err    string
+}
+
+// render returns lines of screen.
+// tasks must be sorted by order of routine.
+func render(s state, tasks []timeserver.Task) []string {
+	lines := make([]string, 0, 16)
+	lines = append(lines, bigText(clock(s.left))...)
+	lines = append(lines, "")
+	lines = append(lines, progressBar(s.left, s.task.Range))
+
+	position := s.task.Index
+	if position == 0 {
+		position = 1
+	}
+	lines = append(lines, fmt.Sprintf("%s  %s  (%d/%d)", s.task.Name, s.status, position, len(tasks)))
+	if s.err != "" {
+		lines = append(lines, fmt.Sprintf("error: %s", s.err))
+	}
+	for i := 0; i < upcomings && position+i < len(tasks); i++ {
+		next := tasks[position+i]
+		lines = append(lines, fmt.Sprintf("next: %s %s", next.Name, next.Range.Round(time.Second)))
+	}
+	lines = append(lines, "", keyHelp)
+	return lines
+}
+
+// clock formats left time. e.g. "04:58", "1:00:00"
+func clock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	d = d.Round(time.Second)
+	h, m, s := int(d/time.Hour), int(d%time.Hour/time.Minute), int(d%time.Minute/time.Second)
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
+
+func bigText(text string) []string {
+	lines := make([]string, 5)
+	for i := range lines {
+		parts := make([]string, 0, len(text))
+		for _, r := range text {
+			parts = append(parts, bigDigits[r][i])
+		}
+		lines[i] = strings.Join(parts, " ")
+	}
+	return lines
+}
+
+func progressBar(left, total time.Duration) string {
+	var ratio float64
+	if total > 0 {
+		ratio = float64(total-left) / float64(total)
+	}
+	if ratio < 0 {
+		ratio = 0
+	}
+	if ratio > 1 {
+		ratio = 1
+	}
+	done := int(ratio * barWidth)
+	return fmt.Sprintf("[%s%s] %3d%%",
+		strings.Repeat("█", done),
+		strings.Repeat("░", barWidth-done),
+		int(ratio*100),
+	)
+}
@@ -0,0 +1,234 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/komem3/goalarm/internal/timeserver"
+)
+
+const (
+	drawInterval = time.Second / 5
+	pollInterval = time.Second
+)
+
+// ServeFunc runs timer which reads commands from r and writes json results to w.
+type ServeFunc func(r io.Reader, w io.Writer) error
+
+type ui struct {
+	screen tcell.Screen
+	tasks  []timeserver.Task
+	now    func() time.Time
+
+	mu         sync.Mutex
+	state      state
+	receivedAt time.Time
+}
+
+// Run runs serve on terminal UI.
+// Keys are converted to commands, and results are drawn as live countdown.
+func Run(tasks []timeserver.Task, serve ServeFunc) error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	defer screen.Fini()
+	return newUI(screen, tasks).run(serve)
+}
+
+func newUI(screen tcell.Screen, tasks []timeserver.Task) *ui {
+	sorted := make([]timeserver.Task, len(tasks))
+	copy(sorted, tasks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Index < sorted[j].Index
+	})
+	u := &ui{
+		screen: screen,
+		tasks:  sorted,
+		now:    time.Now,
+	}
+	if len(sorted) > 0 {
+		u.state = state{
+			status: timeserver.RunningStatus,
+			left:   sorted[0].Range,
+			task:   sorted[0],
+		}
+	}
+	u.receivedAt = u.now()
+	return u
+}
+
+func (u *ui) run(serve ServeFunc) error {
+	cmdR, cmdW := io.Pipe()
+	resR, resW := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		err := serve(cmdR, resW)
+		resW.Close()
+		done <- err
+	}()
+	go u.receive(resR)
+
+	commands := make(chan timeserver.Command, 16)
+	go func() {
+		for c := range commands {
+			if _, err := fmt.Fprintf(cmdW, "%s\n", c); err != nil {
+				return
+			}
+		}
+	}()
+	defer close(commands)
+	defer cmdR.Close()
+
+	events := make(chan tcell.Event)
+	quit := make(chan struct{})
+	defer close(quit)
+	go func() {
+		for {
+			ev := u.screen.PollEvent()
+			if ev == nil {
+				return
+			}
+			select {
+			case events <- ev:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	draw := time.NewTicker(drawInterval)
+	defer draw.Stop()
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+
+	send := func(c timeserver.Command) {
+		select {
+		case commands <- c:
+		default:
+		}
+	}
+	send(timeserver.GetCommand)
+	u.draw()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case ev := <-events:
+			switch ev := ev.(type) {
+			case *tcell.EventKey:
+				if c, ok := u.command(ev); ok {
+					send(c)
+				}
+			case *tcell.EventResize:
+				u.screen.Sync()
+			}
+		case <-poll.C:
+			send(timeserver.GetCommand)
+		case <-draw.C:
+			u.draw()
+		}
+	}
+}
+
+// command converts key to command.
+func (u *ui) command(ev *tcell.EventKey) (timeserver.Command, bool) {
+	switch ev.Key() {
+	case tcell.KeyCtrlC, tcell.KeyEscape:
+		return timeserver.StopCommand, true
+	case tcell.KeyRune:
+	default:
+		return "", false
+	}
+	switch ev.Rune() {
+	case ' ':
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		if u.state.status == timeserver.PauseStatus {
+			return timeserver.StartCommand, true
+		}
+		return timeserver.PauseCommand, true
+	case 'r':
+		return timeserver.RestartCommand, true
+	case 'n':
+		return timeserver.NextCommand, true
+	case 'q':
+		return timeserver.StopCommand, true
+	}
+	return "", false
+}
+
+type resultJson struct {
+	Type   string            `json:"type"`
+	Status timeserver.Status `json:"status"`
+	Left   string            `json:"left"`
+	Error  string            `json:"error"`
+	Task   struct {
+		Index int    `json:"index"`
+		Range string `json:"range"`
+		Name  string `json:"name"`
+	} `json:"task"`
+}
+
+// receive updates state by json results.
+func (u *ui) receive(r io.Reader) {
+	dec := json.NewDecoder(r)
+	for {
+		var res resultJson
+		if err := dec.Decode(&res); err != nil {
+			return
+		}
+		// hello and capabilities messages
+		if res.Type != "" {
+			continue
+		}
+		left, _ := time.ParseDuration(res.Left)
+		rng, _ := time.ParseDuration(res.Task.Range)
+
+		u.mu.Lock()
+		u.state = state{
+			status: res.Status,
+			left:   left,
+			task: timeserver.Task{
+				Index: res.Task.Index,
+				Range: rng,
+				Name:  res.Task.Name,
+			},
+			err: res.Error,
+		}
+		u.receivedAt = u.now()
+		u.mu.Unlock()
+	}
+}
+
+// current returns state counting down from the last result.
+func (u *ui) current() state {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	s := u.state
+	if s.status == timeserver.RunningStatus {
+		s.left -= u.now().Sub(u.receivedAt)
+	}
+	return s
+}
+
+func (u *ui) draw() {
+	u.screen.Clear()
+	for y, line := range render(u.current(), u.tasks) {
+		x := 0
+		for _, r := range line {
+			u.screen.SetContent(x, y, r, nil, tcell.StyleDefault)
+			x++
+		}
+	}
+	u.screen.Show()
+}
@@ -0,0 +1,121 @@
+package tui_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/google/go-cmp/cmp"
+	"github.com/komem3/goalarm/internal/timeserver"
+	"github.com/komem3/goalarm/internal/tui"
+)
+
+var routine = []timeserver.Task{
+	{Index: 2, Range: 5 * time.Minute, Name: "break"},
+	{Index: 1, Range: 20 * time.Minute, Name: "working"},
+	{Index: 3, Range: time.Hour, Name: "lunch"},
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		given tui.State
+		want  []string
+	}{
+		{
+			"first step",
+			tui.NewState(timeserver.RunningStatus, 15*time.Minute, routine[1]),
+			[]string{
+				"  █ ███   ███ ███",
+				"  █ █   █ █ █ █ █",
+				"  █ ███   █ █ █ █",
+				"  █   █ █ █ █ █ █",
+				"  █ ███   ███ ███",
+				"",
+				"[██████████░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░]  25%",
+				"working  running  (1/3)",
+				"next: break 5m0s",
+				"next: lunch 1h0m0s",
+				"",
+				"space: pause/start  r: restart  n: next  q: stop",
+			},
+		},
+		{
+			"last step",
+			tui.NewState(timeserver.PauseStatus, time.Hour, routine[2]),
+			[]string{
+				"  █   ███ ███   ███ ███",
+				"  █ █ █ █ █ █ █ █ █ █ █",
+				"  █   █ █ █ █   █ █ █ █",
+				"  █ █ █ █ █ █ █ █ █ █ █",
+				"  █   ███ ███   ███ ███",
+				"",
+				"[░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░░]   0%",
+				"lunch  pause  (3/3)",
+				"",
+				"space: pause/start  r: restart  n: next  q: stop",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tasks := []timeserver.Task{routine[1], routine[0], routine[2]}
+			if diff := cmp.Diff(tui.Render(tt.given, tasks), tt.want); diff != "" {
+				t.Errorf("render: given(-), want(+)\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestRun_Keys(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+
+	var commands []string
+	serve := func(r io.Reader, w io.Writer) error {
+		jw := json.NewEncoder(w)
+		status := timeserver.RunningStatus
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			c := timeserver.Command(scanner.Text())
+			commands = append(commands, string(c))
+			switch c {
+			case timeserver.GetCommand:
+				if len(commands) == 1 {
+					go screen.InjectKey(tcell.KeyRune, ' ', tcell.ModNone)
+				}
+			case timeserver.PauseCommand:
+				status = timeserver.PauseStatus
+				go func() {
+					time.Sleep(50 * time.Millisecond)
+					screen.InjectKey(tcell.KeyRune, ' ', tcell.ModNone)
+				}()
+			case timeserver.StartCommand:
+				status = timeserver.RunningStatus
+				go screen.InjectKey(tcell.KeyRune, 'q', tcell.ModNone)
+			case timeserver.StopCommand:
+				return nil
+			}
+			if err := jw.Encode(timeserver.Result{Status: status, Left: "10s", Task: routine[1]}); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	}
+
+	if err := tui.RunOn(screen, routine, serve); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"get", "pause", "start", "stop"}
+	if diff := cmp.Diff(commands, want); diff != "" {
+		t.Errorf("commands: given(-), want(+)\n%s\n", diff)
+	}
+}